@@ -20,7 +20,12 @@ package count
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
+	"sync"
 	"sync/atomic"
+
+	"go.chromium.org/luci/common/errors"
 )
 
 type counter struct {
@@ -31,15 +36,33 @@ func (c *counter) increment() {
 	atomic.AddInt32(&c.value, 1)
 }
 
+func (c *counter) add(n int) {
+	if n != 0 {
+		atomic.AddInt32(&c.value, int32(n))
+	}
+}
+
 func (c *counter) get() int {
 	return int(atomic.LoadInt32(&c.value))
 }
 
 // Entry is a success/fail pair for a single API method. It's returned
 // by the Counter interface.
+//
+// For batch (Multi) methods, Entry also counts the results of the individual
+// items in each call. Every Entry additionally keeps a breakdown of its counts
+// by the namespace which was active on the context at the time of the call.
+// Use Snapshot to retrieve the full set of counts.
 type Entry struct {
 	successes counter
 	errors    counter
+
+	itemSuccesses counter
+	itemErrors    counter
+
+	// nsLock guards namespaces, the per-namespace breakdown of this Entry.
+	nsLock     sync.RWMutex
+	namespaces map[string]*Entry
 }
 
 func (e *Entry) String() string {
@@ -60,15 +83,190 @@ func (e *Entry) Errors() int {
 	return e.errors.get()
 }
 
-func (e *Entry) up(errs ...error) error {
-	err := error(nil)
-	if len(errs) > 0 {
-		err = errs[0]
+// ItemSuccesses returns the number of individual items which succeeded in
+// calls to this Entry's batch method. It's always 0 for non-batch methods.
+func (e *Entry) ItemSuccesses() int {
+	return e.itemSuccesses.get()
+}
+
+// ItemErrors returns the number of individual items which failed in calls to
+// this Entry's batch method. It's always 0 for non-batch methods.
+func (e *Entry) ItemErrors() int {
+	return e.itemErrors.get()
+}
+
+// Namespace returns a snapshot of the counts for calls which were made while
+// namespace was active. If no calls were made in namespace, the returned
+// snapshot is all zeros.
+func (e *Entry) Namespace(namespace string) EntrySnapshot {
+	e.nsLock.RLock()
+	sub := e.namespaces[namespace]
+	e.nsLock.RUnlock()
+
+	if sub == nil {
+		return EntrySnapshot{}
+	}
+	return sub.snapshot(false)
+}
+
+// Snapshot returns a point-in-time copy of this Entry's counts, including the
+// per-namespace breakdown.
+func (e *Entry) Snapshot() EntrySnapshot {
+	return e.snapshot(true)
+}
+
+func (e *Entry) snapshot(withNamespaces bool) EntrySnapshot {
+	ret := EntrySnapshot{
+		Successes:     e.Successes(),
+		Errors:        e.Errors(),
+		ItemSuccesses: e.ItemSuccesses(),
+		ItemErrors:    e.ItemErrors(),
+	}
+	if withNamespaces {
+		e.nsLock.RLock()
+		defer e.nsLock.RUnlock()
+
+		ret.Namespaces = make(map[string]EntrySnapshot, len(e.namespaces))
+		for ns, sub := range e.namespaces {
+			ret.Namespaces[ns] = sub.snapshot(false)
+		}
+	}
+	return ret
+}
+
+// inNamespace returns the per-namespace Entry for namespace, creating it if
+// necessary.
+func (e *Entry) inNamespace(namespace string) *Entry {
+	e.nsLock.RLock()
+	sub := e.namespaces[namespace]
+	e.nsLock.RUnlock()
+	if sub != nil {
+		return sub
+	}
+
+	e.nsLock.Lock()
+	defer e.nsLock.Unlock()
+	if sub = e.namespaces[namespace]; sub == nil {
+		if e.namespaces == nil {
+			e.namespaces = map[string]*Entry{}
+		}
+		sub = &Entry{}
+		e.namespaces[namespace] = sub
 	}
+	return sub
+}
+
+func (e *Entry) record(err error, it *itemCounts) {
 	if err == nil {
 		e.successes.increment()
 	} else {
 		e.errors.increment()
 	}
+	if it != nil {
+		e.itemSuccesses.add(it.successes.get())
+		e.itemErrors.add(it.errors.get())
+	}
+}
+
+func (e *Entry) up(namespace string, errs ...error) error {
+	err := error(nil)
+	if len(errs) > 0 {
+		err = errs[0]
+	}
+	e.record(err, nil)
+	e.inNamespace(namespace).record(err, nil)
+	return err
+}
+
+// upItems is like up, but also records the per-item results collected in it
+// for a batch method call.
+//
+// If the call didn't report any items to its callback and err is an
+// errors.MultiError, each element of err is counted as an item instead.
+func (e *Entry) upItems(namespace string, it *itemCounts, err error) error {
+	if it.successes.get() == 0 && it.errors.get() == 0 {
+		if me, ok := err.(errors.MultiError); ok {
+			for _, ierr := range me {
+				it.up(ierr)
+			}
+		}
+	}
+	e.record(err, it)
+	e.inNamespace(namespace).record(err, it)
 	return err
 }
+
+// itemCounts accumulates the per-item results of a single batch method call.
+type itemCounts struct {
+	successes counter
+	errors    counter
+}
+
+func (it *itemCounts) up(err error) {
+	if err == nil {
+		it.successes.increment()
+	} else {
+		it.errors.increment()
+	}
+}
+
+// EntrySnapshot is a point-in-time copy of the counts in an Entry.
+type EntrySnapshot struct {
+	// Successes and Errors are the number of successful and unsuccessful calls.
+	Successes int
+	Errors    int
+
+	// ItemSuccesses and ItemErrors are the number of individual items which
+	// succeeded or failed in batch (Multi) method calls.
+	ItemSuccesses int
+	ItemErrors    int
+
+	// Namespaces maps each namespace which was active during at least one call
+	// to the counts for the calls made in that namespace. It's only populated by
+	// Entry.Snapshot, and the EntrySnapshots it contains have nil Namespaces.
+	Namespaces map[string]EntrySnapshot
+}
+
+// Total is the number of calls. It's Successes+Errors.
+func (s EntrySnapshot) Total() int64 { return int64(s.Successes) + int64(s.Errors) }
+
+// Snapshot returns a snapshot of every Entry in counter, keyed by the name of
+// its field. counter must be a pointer to one of this package's counter
+// objects (e.g. *DSCounter), as returned by the Filter functions.
+func Snapshot(counter interface{}) map[string]EntrySnapshot {
+	v := reflect.ValueOf(counter)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("count.Snapshot: expected pointer to counter struct, got %T", counter))
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	ret := make(map[string]EntrySnapshot, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath != "" {
+			// unexported
+			continue
+		}
+		if e, ok := v.Field(i).Addr().Interface().(*Entry); ok {
+			ret[t.Field(i).Name] = e.Snapshot()
+		}
+	}
+	return ret
+}
+
+// Namespaces returns the sorted list of namespaces in which any method of
+// counter was called. counter has the same requirements as in Snapshot.
+func Namespaces(counter interface{}) []string {
+	seen := map[string]struct{}{}
+	for _, snap := range Snapshot(counter) {
+		for ns := range snap.Namespaces {
+			seen[ns] = struct{}{}
+		}
+	}
+	ret := make([]string, 0, len(seen))
+	for ns := range seen {
+		ret = append(ret, ns)
+	}
+	sort.Strings(ret)
+	return ret
+}
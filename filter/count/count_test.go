@@ -16,6 +16,7 @@ package count
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -27,12 +28,13 @@ import (
 	"go.chromium.org/gae/service/memcache"
 	"go.chromium.org/gae/service/taskqueue"
 	"go.chromium.org/gae/service/user"
+	"go.chromium.org/luci/common/errors"
 	. "go.chromium.org/luci/common/testing/assertions"
 	"golang.org/x/net/context"
 )
 
 func shouldHaveSuccessesAndErrors(actual interface{}, expected ...interface{}) string {
-	a := actual.(Entry)
+	a := actual.(*Entry)
 	if len(expected) != 2 {
 		panic("Invalid number of expected, should be 2 (successes, errors).")
 	}
@@ -111,6 +113,71 @@ func TestCount(t *testing.T) {
 		})
 	})
 
+	Convey("Test Count filter items and namespaces", t, func() {
+		c, fb := featureBreaker.FilterRDS(memory.Use(context.Background()), nil)
+		c, ctr := FilterRDS(c)
+
+		mkVals := func(c context.Context, ids ...int64) []ds.PropertyMap {
+			vals := make([]ds.PropertyMap, len(ids))
+			for i, id := range ids {
+				vals[i] = ds.PropertyMap{
+					"Val":  ds.MkProperty(100),
+					"$key": ds.MkPropertyNI(ds.NewKey(c, "Kind", "", id, nil)),
+				}
+			}
+			return vals
+		}
+
+		Convey("per-item results are counted for batch methods", func() {
+			So(ds.Put(c, mkVals(c, 1, 2)), ShouldBeNil)
+			So(ctr.PutMulti.ItemSuccesses(), ShouldEqual, 2)
+			So(ctr.PutMulti.ItemErrors(), ShouldEqual, 0)
+
+			So(ds.Get(c, mkVals(c, 1, 2, 3)), ShouldErrLike, ds.ErrNoSuchEntity)
+			So(ctr.GetMulti.Snapshot(), ShouldResemble, EntrySnapshot{
+				Successes:     1,
+				ItemSuccesses: 2,
+				ItemErrors:    1,
+				Namespaces: map[string]EntrySnapshot{
+					"": {Successes: 1, ItemSuccesses: 2, ItemErrors: 1},
+				},
+			})
+		})
+
+		Convey("a MultiError partial failure is counted per item", func() {
+			fb.BreakFeatures(errors.MultiError{nil, ds.ErrNoSuchEntity, nil}, "GetMulti")
+
+			vals := mkVals(c, 1, 2, 3)
+			So(ds.Get(c, vals[0], vals[1], vals[2]), ShouldResemble,
+				errors.MultiError{nil, ds.ErrNoSuchEntity, nil})
+			So(ctr.GetMulti.Errors(), ShouldEqual, 1)
+			So(ctr.GetMulti.ItemSuccesses(), ShouldEqual, 2)
+			So(ctr.GetMulti.ItemErrors(), ShouldEqual, 1)
+		})
+
+		Convey("counts are bucketed by namespace", func() {
+			a := info.MustNamespace(c, "tenant-a")
+
+			So(ds.Put(c, mkVals(c, 1)), ShouldBeNil)
+			So(ds.Put(a, mkVals(a, 1, 2)), ShouldBeNil)
+			So(ds.Put(a, mkVals(a, 3)), ShouldBeNil)
+
+			So(ctr.PutMulti.Successes(), ShouldEqual, 3)
+			So(ctr.PutMulti.Namespace(""), ShouldResemble, EntrySnapshot{
+				Successes: 1, ItemSuccesses: 1})
+			So(ctr.PutMulti.Namespace("tenant-a"), ShouldResemble, EntrySnapshot{
+				Successes: 2, ItemSuccesses: 3})
+			So(ctr.PutMulti.Namespace("tenant-b"), ShouldResemble, EntrySnapshot{})
+
+			So(Namespaces(ctr), ShouldResemble, []string{"", "tenant-a"})
+
+			snap := Snapshot(ctr)
+			So(snap, ShouldHaveLength, 8)
+			So(snap["PutMulti"].Total(), ShouldEqual, 3)
+			So(snap["GetMulti"].Total(), ShouldEqual, 0)
+		})
+	})
+
 	Convey("works for memcache", t, func() {
 		c, ctr := FilterMC(memory.Use(context.Background()))
 		So(c, ShouldNotBeNil)
@@ -124,9 +191,9 @@ func TestCount(t *testing.T) {
 		_, err = memcache.GetKey(c, "hello")
 		die(err)
 
-		So(ctr.SetMulti, shouldHaveSuccessesAndErrors, 1, 0)
-		So(ctr.GetMulti, shouldHaveSuccessesAndErrors, 2, 0)
-		So(ctr.NewItem, shouldHaveSuccessesAndErrors, 3, 0)
+		So(&ctr.SetMulti, shouldHaveSuccessesAndErrors, 1, 0)
+		So(&ctr.GetMulti, shouldHaveSuccessesAndErrors, 2, 0)
+		So(&ctr.NewItem, shouldHaveSuccessesAndErrors, 3, 0)
 	})
 
 	Convey("works for taskqueue", t, func() {
@@ -138,7 +205,7 @@ func TestCount(t *testing.T) {
 		So(taskqueue.Add(c, "DNE_QUEUE", &taskqueue.Task{Name: "wat"}),
 			ShouldErrLike, "UNKNOWN_QUEUE")
 
-		So(ctr.AddMulti, shouldHaveSuccessesAndErrors, 1, 1)
+		So(&ctr.AddMulti, shouldHaveSuccessesAndErrors, 1, 1)
 	})
 
 	Convey("works for global info", t, func() {
@@ -153,7 +220,7 @@ func TestCount(t *testing.T) {
 		_, err = info.Namespace(c, "boom")
 		So(err, ShouldErrLike, `"Namespace" is broken`)
 
-		So(ctr.Namespace, shouldHaveSuccessesAndErrors, 1, 1)
+		So(&ctr.Namespace, shouldHaveSuccessesAndErrors, 1, 1)
 	})
 
 	Convey("works for user", t, func() {
@@ -168,7 +235,7 @@ func TestCount(t *testing.T) {
 		_, err = user.CurrentOAuth(c, "foo")
 		So(err, ShouldErrLike, `"CurrentOAuth" is broken`)
 
-		So(ctr.CurrentOAuth, shouldHaveSuccessesAndErrors, 1, 1)
+		So(&ctr.CurrentOAuth, shouldHaveSuccessesAndErrors, 1, 1)
 	})
 
 	Convey("works for mail", t, func() {
@@ -192,10 +259,55 @@ func TestCount(t *testing.T) {
 		})
 		So(err, ShouldErrLike, `"Send" is broken`)
 
-		So(ctr.Send, shouldHaveSuccessesAndErrors, 1, 1)
+		So(&ctr.Send, shouldHaveSuccessesAndErrors, 1, 1)
 	})
 }
 
+func TestCountConcurrent(t *testing.T) {
+	t.Parallel()
+
+	c, ctr := FilterRDS(memory.Use(context.Background()))
+
+	const workers = 16
+	const rounds = 20
+
+	namespaces := []string{"", "a", "b", "c"}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			c := info.MustNamespace(c, namespaces[i%len(namespaces)])
+			for j := 0; j < rounds; j++ {
+				pm := ds.PropertyMap{
+					"$key": ds.MkPropertyNI(ds.NewKey(c, "Kind", "", int64(i*rounds+j+1), nil)),
+				}
+				if err := ds.Put(c, pm); err != nil {
+					t.Error(err)
+				}
+				_ = Snapshot(ctr)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if v := ctr.PutMulti.Successes(); v != workers*rounds {
+		t.Errorf("PutMulti.Successes() = %d, expected %d", v, workers*rounds)
+	}
+	if v := ctr.PutMulti.ItemSuccesses(); v != workers*rounds {
+		t.Errorf("PutMulti.ItemSuccesses() = %d, expected %d", v, workers*rounds)
+	}
+	for _, ns := range namespaces {
+		expected := workers / len(namespaces) * rounds
+		if v := ctr.PutMulti.Namespace(ns).Successes; v != expected {
+			t.Errorf("PutMulti.Namespace(%q).Successes = %d, expected %d", ns, v, expected)
+		}
+	}
+}
+
 func ExampleFilterRDS() {
 	// Set up your context using a base service implementation (memory or prod)
 	c := memory.Use(context.Background())
@@ -46,7 +46,8 @@ type InfoCounter struct {
 }
 
 type infoCounter struct {
-	c *InfoCounter
+	c  *InfoCounter
+	ns string
 
 	gi info.RawInterface
 }
@@ -54,99 +55,99 @@ type infoCounter struct {
 var _ info.RawInterface = (*infoCounter)(nil)
 
 func (g *infoCounter) AppID() string {
-	_ = g.c.AppID.up()
+	_ = g.c.AppID.up(g.ns)
 	return g.gi.AppID()
 }
 
 func (g *infoCounter) FullyQualifiedAppID() string {
-	_ = g.c.FullyQualifiedAppID.up()
+	_ = g.c.FullyQualifiedAppID.up(g.ns)
 	return g.gi.FullyQualifiedAppID()
 }
 
 func (g *infoCounter) GetNamespace() string {
-	_ = g.c.GetNamespace.up()
+	_ = g.c.GetNamespace.up(g.ns)
 	return g.gi.GetNamespace()
 }
 
 func (g *infoCounter) Datacenter() string {
-	_ = g.c.Datacenter.up()
+	_ = g.c.Datacenter.up(g.ns)
 	return g.gi.Datacenter()
 }
 
 func (g *infoCounter) DefaultVersionHostname() string {
-	_ = g.c.DefaultVersionHostname.up()
+	_ = g.c.DefaultVersionHostname.up(g.ns)
 	return g.gi.DefaultVersionHostname()
 }
 
 func (g *infoCounter) InstanceID() string {
-	_ = g.c.InstanceID.up()
+	_ = g.c.InstanceID.up(g.ns)
 	return g.gi.InstanceID()
 }
 
 func (g *infoCounter) IsDevAppServer() bool {
-	_ = g.c.IsDevAppServer.up()
+	_ = g.c.IsDevAppServer.up(g.ns)
 	return g.gi.IsDevAppServer()
 }
 
 func (g *infoCounter) IsOverQuota(err error) bool {
-	_ = g.c.IsOverQuota.up()
+	_ = g.c.IsOverQuota.up(g.ns)
 	return g.gi.IsOverQuota(err)
 }
 
 func (g *infoCounter) IsTimeoutError(err error) bool {
-	_ = g.c.IsTimeoutError.up()
+	_ = g.c.IsTimeoutError.up(g.ns)
 	return g.gi.IsTimeoutError(err)
 }
 
 func (g *infoCounter) ModuleHostname(module, version, instance string) (string, error) {
 	ret, err := g.gi.ModuleHostname(module, version, instance)
-	return ret, g.c.ModuleHostname.up(err)
+	return ret, g.c.ModuleHostname.up(g.ns, err)
 }
 
 func (g *infoCounter) ModuleName() string {
-	_ = g.c.ModuleName.up()
+	_ = g.c.ModuleName.up(g.ns)
 	return g.gi.ModuleName()
 }
 
 func (g *infoCounter) RequestID() string {
-	_ = g.c.RequestID.up()
+	_ = g.c.RequestID.up(g.ns)
 	return g.gi.RequestID()
 }
 
 func (g *infoCounter) ServerSoftware() string {
-	_ = g.c.ServerSoftware.up()
+	_ = g.c.ServerSoftware.up(g.ns)
 	return g.gi.ServerSoftware()
 }
 
 func (g *infoCounter) ServiceAccount() (string, error) {
 	ret, err := g.gi.ServiceAccount()
-	return ret, g.c.ServiceAccount.up(err)
+	return ret, g.c.ServiceAccount.up(g.ns, err)
 }
 
 func (g *infoCounter) VersionID() string {
-	_ = g.c.VersionID.up()
+	_ = g.c.VersionID.up(g.ns)
 	return g.gi.VersionID()
 }
 
 func (g *infoCounter) Namespace(namespace string) (c context.Context, err error) {
 	c, err = g.gi.Namespace(namespace)
-	g.c.Namespace.up(err)
+	g.c.Namespace.up(g.ns, err)
 	return
 }
 
 func (g *infoCounter) AccessToken(scopes ...string) (string, time.Time, error) {
 	token, expiry, err := g.gi.AccessToken(scopes...)
-	return token, expiry, g.c.AccessToken.up(err)
+	return token, expiry, g.c.AccessToken.up(g.ns, err)
 }
 
 func (g *infoCounter) PublicCertificates() ([]info.Certificate, error) {
 	ret, err := g.gi.PublicCertificates()
-	return ret, g.c.PublicCertificates.up(err)
+	return ret, g.c.PublicCertificates.up(g.ns, err)
 }
 
 func (g *infoCounter) SignBytes(bytes []byte) (string, []byte, error) {
 	keyName, signature, err := g.gi.SignBytes(bytes)
-	return keyName, signature, g.c.SignBytes.up(err)
+	return keyName, signature, g.c.SignBytes.up(g.ns, err)
 }
 
 func (g *infoCounter) GetTestable() info.Testable {
@@ -157,6 +158,6 @@ func (g *infoCounter) GetTestable() info.Testable {
 func FilterGI(c context.Context) (context.Context, *InfoCounter) {
 	state := &InfoCounter{}
 	return info.AddFilters(c, func(ic context.Context, gi info.RawInterface) info.RawInterface {
-		return &infoCounter{state, gi}
+		return &infoCounter{state, gi.GetNamespace(), gi}
 	}), state
 }
@@ -15,6 +15,7 @@
 package count
 
 import (
+	"go.chromium.org/gae/service/info"
 	"go.chromium.org/gae/service/mail"
 	"golang.org/x/net/context"
 )
@@ -26,7 +27,8 @@ type MailCounter struct {
 }
 
 type mailCounter struct {
-	c *MailCounter
+	c  *MailCounter
+	ns string
 
 	m mail.RawInterface
 }
@@ -34,11 +36,11 @@ type mailCounter struct {
 var _ mail.RawInterface = (*mailCounter)(nil)
 
 func (m *mailCounter) Send(msg *mail.Message) error {
-	return m.c.Send.up(m.m.Send(msg))
+	return m.c.Send.up(m.ns, m.m.Send(msg))
 }
 
 func (m *mailCounter) SendToAdmins(msg *mail.Message) error {
-	return m.c.SendToAdmins.up(m.m.SendToAdmins(msg))
+	return m.c.SendToAdmins.up(m.ns, m.m.SendToAdmins(msg))
 }
 
 func (m *mailCounter) GetTestable() mail.Testable {
@@ -49,6 +51,6 @@ func (m *mailCounter) GetTestable() mail.Testable {
 func FilterMail(c context.Context) (context.Context, *MailCounter) {
 	state := &MailCounter{}
 	return mail.AddFilters(c, func(ic context.Context, u mail.RawInterface) mail.RawInterface {
-		return &mailCounter{state, u}
+		return &mailCounter{state, info.GetNamespace(ic), u}
 	}), state
 }
@@ -17,6 +17,7 @@ package count
 import (
 	"golang.org/x/net/context"
 
+	"go.chromium.org/gae/service/info"
 	mc "go.chromium.org/gae/service/memcache"
 )
 
@@ -34,7 +35,8 @@ type MCCounter struct {
 }
 
 type mcCounter struct {
-	c *MCCounter
+	c  *MCCounter
+	ns string
 
 	mc mc.RawInterface
 }
@@ -42,46 +44,71 @@ type mcCounter struct {
 var _ mc.RawInterface = (*mcCounter)(nil)
 
 func (m *mcCounter) NewItem(key string) mc.Item {
-	_ = m.c.NewItem.up()
+	_ = m.c.NewItem.up(m.ns)
 	return m.mc.NewItem(key)
 }
 
 func (m *mcCounter) GetMulti(keys []string, cb mc.RawItemCB) error {
-	return m.c.GetMulti.up(m.mc.GetMulti(keys, cb))
+	it := itemCounts{}
+	err := m.mc.GetMulti(keys, func(item mc.Item, err error) {
+		it.up(err)
+		cb(item, err)
+	})
+	return m.c.GetMulti.upItems(m.ns, &it, err)
 }
 
 func (m *mcCounter) AddMulti(items []mc.Item, cb mc.RawCB) error {
-	return m.c.AddMulti.up(m.mc.AddMulti(items, cb))
+	it := itemCounts{}
+	err := m.mc.AddMulti(items, func(err error) {
+		it.up(err)
+		cb(err)
+	})
+	return m.c.AddMulti.upItems(m.ns, &it, err)
 }
 
 func (m *mcCounter) SetMulti(items []mc.Item, cb mc.RawCB) error {
-	return m.c.SetMulti.up(m.mc.SetMulti(items, cb))
+	it := itemCounts{}
+	err := m.mc.SetMulti(items, func(err error) {
+		it.up(err)
+		cb(err)
+	})
+	return m.c.SetMulti.upItems(m.ns, &it, err)
 }
 
 func (m *mcCounter) DeleteMulti(keys []string, cb mc.RawCB) error {
-	return m.c.DeleteMulti.up(m.mc.DeleteMulti(keys, cb))
+	it := itemCounts{}
+	err := m.mc.DeleteMulti(keys, func(err error) {
+		it.up(err)
+		cb(err)
+	})
+	return m.c.DeleteMulti.upItems(m.ns, &it, err)
 }
 
 func (m *mcCounter) CompareAndSwapMulti(items []mc.Item, cb mc.RawCB) error {
-	return m.c.CompareAndSwapMulti.up(m.mc.CompareAndSwapMulti(items, cb))
+	it := itemCounts{}
+	err := m.mc.CompareAndSwapMulti(items, func(err error) {
+		it.up(err)
+		cb(err)
+	})
+	return m.c.CompareAndSwapMulti.upItems(m.ns, &it, err)
 }
 
-func (m *mcCounter) Flush() error { return m.c.Flush.up(m.mc.Flush()) }
+func (m *mcCounter) Flush() error { return m.c.Flush.up(m.ns, m.mc.Flush()) }
 
 func (m *mcCounter) Increment(key string, delta int64, initialValue *uint64) (newValue uint64, err error) {
 	ret, err := m.mc.Increment(key, delta, initialValue)
-	return ret, m.c.Increment.up(err)
+	return ret, m.c.Increment.up(m.ns, err)
 }
 
 func (m *mcCounter) Stats() (*mc.Statistics, error) {
 	ret, err := m.mc.Stats()
-	return ret, m.c.Stats.up(err)
+	return ret, m.c.Stats.up(m.ns, err)
 }
 
 // FilterMC installs a counter Memcache filter in the context.
 func FilterMC(c context.Context) (context.Context, *MCCounter) {
 	state := &MCCounter{}
 	return mc.AddRawFilters(c, func(ic context.Context, mc mc.RawInterface) mc.RawInterface {
-		return &mcCounter{state, mc}
+		return &mcCounter{state, info.GetNamespace(ic), mc}
 	}), state
 }
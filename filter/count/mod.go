@@ -17,6 +17,7 @@ package count
 import (
 	"golang.org/x/net/context"
 
+	"go.chromium.org/gae/service/info"
 	"go.chromium.org/gae/service/module"
 )
 
@@ -32,7 +33,8 @@ type ModuleCounter struct {
 }
 
 type modCounter struct {
-	c *ModuleCounter
+	c  *ModuleCounter
+	ns string
 
 	mod module.RawInterface
 }
@@ -41,40 +43,40 @@ var _ module.RawInterface = (*modCounter)(nil)
 
 func (m *modCounter) List() ([]string, error) {
 	ret, err := m.mod.List()
-	return ret, m.c.List.up(err)
+	return ret, m.c.List.up(m.ns, err)
 }
 
 func (m *modCounter) NumInstances(mod, ver string) (int, error) {
 	ret, err := m.mod.NumInstances(mod, ver)
-	return ret, m.c.NumInstances.up(err)
+	return ret, m.c.NumInstances.up(m.ns, err)
 }
 
 func (m *modCounter) SetNumInstances(mod, ver string, instances int) error {
-	return m.c.SetNumInstances.up(m.mod.SetNumInstances(mod, ver, instances))
+	return m.c.SetNumInstances.up(m.ns, m.mod.SetNumInstances(mod, ver, instances))
 }
 
 func (m *modCounter) Versions(mod string) ([]string, error) {
 	ret, err := m.mod.Versions(mod)
-	return ret, m.c.Versions.up(err)
+	return ret, m.c.Versions.up(m.ns, err)
 }
 
 func (m *modCounter) DefaultVersion(mod string) (string, error) {
 	ret, err := m.mod.DefaultVersion(mod)
-	return ret, m.c.DefaultVersion.up(err)
+	return ret, m.c.DefaultVersion.up(m.ns, err)
 }
 
 func (m *modCounter) Start(mod, ver string) error {
-	return m.c.Start.up(m.mod.Start(mod, ver))
+	return m.c.Start.up(m.ns, m.mod.Start(mod, ver))
 }
 
 func (m *modCounter) Stop(mod, ver string) error {
-	return m.c.Stop.up(m.mod.Stop(mod, ver))
+	return m.c.Stop.up(m.ns, m.mod.Stop(mod, ver))
 }
 
 // FilterModule installs a counter Module filter in the context.
 func FilterModule(c context.Context) (context.Context, *ModuleCounter) {
 	state := &ModuleCounter{}
 	return module.AddFilters(c, func(ic context.Context, mod module.RawInterface) module.RawInterface {
-		return &modCounter{state, mod}
+		return &modCounter{state, info.GetNamespace(ic), mod}
 	}), state
 }
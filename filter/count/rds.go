@@ -18,6 +18,7 @@ import (
 	"golang.org/x/net/context"
 
 	ds "go.chromium.org/gae/service/datastore"
+	"go.chromium.org/gae/service/info"
 )
 
 // DSCounter is the counter object for the datastore service.
@@ -33,7 +34,8 @@ type DSCounter struct {
 }
 
 type dsCounter struct {
-	c *DSCounter
+	c  *DSCounter
+	ns string
 
 	ds ds.RawInterface
 }
@@ -41,37 +43,57 @@ type dsCounter struct {
 var _ ds.RawInterface = (*dsCounter)(nil)
 
 func (r *dsCounter) AllocateIDs(keys []*ds.Key, cb ds.NewKeyCB) error {
-	return r.c.AllocateIDs.up(r.ds.AllocateIDs(keys, cb))
+	it := itemCounts{}
+	err := r.ds.AllocateIDs(keys, func(idx int, key *ds.Key, err error) error {
+		it.up(err)
+		return cb(idx, key, err)
+	})
+	return r.c.AllocateIDs.upItems(r.ns, &it, err)
 }
 
 func (r *dsCounter) DecodeCursor(s string) (ds.Cursor, error) {
 	cursor, err := r.ds.DecodeCursor(s)
-	return cursor, r.c.DecodeCursor.up(err)
+	return cursor, r.c.DecodeCursor.up(r.ns, err)
 }
 
 func (r *dsCounter) Run(q *ds.FinalizedQuery, cb ds.RawRunCB) error {
-	return r.c.Run.upFilterStop(r.ds.Run(q, cb))
+	return r.c.Run.upFilterStop(r.ns, nil, r.ds.Run(q, cb))
 }
 
 func (r *dsCounter) Count(q *ds.FinalizedQuery) (int64, error) {
 	count, err := r.ds.Count(q)
-	return count, r.c.Count.up(err)
+	return count, r.c.Count.up(r.ns, err)
 }
 
 func (r *dsCounter) RunInTransaction(f func(context.Context) error, opts *ds.TransactionOptions) error {
-	return r.c.RunInTransaction.up(r.ds.RunInTransaction(f, opts))
+	return r.c.RunInTransaction.up(r.ns, r.ds.RunInTransaction(f, opts))
 }
 
 func (r *dsCounter) DeleteMulti(keys []*ds.Key, cb ds.DeleteMultiCB) error {
-	return r.c.DeleteMulti.upFilterStop(r.ds.DeleteMulti(keys, cb))
+	it := itemCounts{}
+	err := r.ds.DeleteMulti(keys, func(idx int, err error) error {
+		it.up(err)
+		return cb(idx, err)
+	})
+	return r.c.DeleteMulti.upFilterStop(r.ns, &it, err)
 }
 
 func (r *dsCounter) GetMulti(keys []*ds.Key, meta ds.MultiMetaGetter, cb ds.GetMultiCB) error {
-	return r.c.GetMulti.upFilterStop(r.ds.GetMulti(keys, meta, cb))
+	it := itemCounts{}
+	err := r.ds.GetMulti(keys, meta, func(idx int, val ds.PropertyMap, err error) error {
+		it.up(err)
+		return cb(idx, val, err)
+	})
+	return r.c.GetMulti.upFilterStop(r.ns, &it, err)
 }
 
 func (r *dsCounter) PutMulti(keys []*ds.Key, vals []ds.PropertyMap, cb ds.NewKeyCB) error {
-	return r.c.PutMulti.upFilterStop(r.ds.PutMulti(keys, vals, cb))
+	it := itemCounts{}
+	err := r.ds.PutMulti(keys, vals, func(idx int, key *ds.Key, err error) error {
+		it.up(err)
+		return cb(idx, key, err)
+	})
+	return r.c.PutMulti.upFilterStop(r.ns, &it, err)
 }
 
 func (r *dsCounter) CurrentTransaction() ds.Transaction {
@@ -93,18 +115,22 @@ func (r *dsCounter) GetTestable() ds.Testable {
 func FilterRDS(c context.Context) (context.Context, *DSCounter) {
 	state := &DSCounter{}
 	return ds.AddRawFilters(c, func(ic context.Context, ds ds.RawInterface) ds.RawInterface {
-		return &dsCounter{state, ds}
+		return &dsCounter{state, info.GetNamespace(ic), ds}
 	}), state
 }
 
-// upFilterStop wraps up, handling the special case datastore.Stop error.
-// datastore.Stop will pass through this function, but, unlike other error
-// codes, will be counted as a success.
-func (e *Entry) upFilterStop(err error) error {
+// upFilterStop wraps up (or upItems, if it is not nil), handling the special
+// case datastore.Stop error. datastore.Stop will pass through this function,
+// but, unlike other error codes, will be counted as a success.
+func (e *Entry) upFilterStop(namespace string, it *itemCounts, err error) error {
 	upErr := err
 	if upErr == ds.Stop {
 		upErr = nil
 	}
-	e.up(upErr)
+	if it != nil {
+		e.upItems(namespace, it, upErr)
+	} else {
+		e.up(namespace, upErr)
+	}
 	return err
 }
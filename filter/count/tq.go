@@ -19,6 +19,7 @@ import (
 
 	"golang.org/x/net/context"
 
+	"go.chromium.org/gae/service/info"
 	tq "go.chromium.org/gae/service/taskqueue"
 )
 
@@ -34,7 +35,8 @@ type TQCounter struct {
 }
 
 type tqCounter struct {
-	c *TQCounter
+	c  *TQCounter
+	ns string
 
 	tq tq.RawInterface
 }
@@ -42,35 +44,45 @@ type tqCounter struct {
 var _ tq.RawInterface = (*tqCounter)(nil)
 
 func (t *tqCounter) AddMulti(tasks []*tq.Task, queueName string, cb tq.RawTaskCB) error {
-	return t.c.AddMulti.up(t.tq.AddMulti(tasks, queueName, cb))
+	it := itemCounts{}
+	err := t.tq.AddMulti(tasks, queueName, func(task *tq.Task, err error) {
+		it.up(err)
+		cb(task, err)
+	})
+	return t.c.AddMulti.upItems(t.ns, &it, err)
 }
 
 func (t *tqCounter) DeleteMulti(tasks []*tq.Task, queueName string, cb tq.RawCB) error {
-	return t.c.DeleteMulti.up(t.tq.DeleteMulti(tasks, queueName, cb))
+	it := itemCounts{}
+	err := t.tq.DeleteMulti(tasks, queueName, func(idx int, err error) {
+		it.up(err)
+		cb(idx, err)
+	})
+	return t.c.DeleteMulti.upItems(t.ns, &it, err)
 }
 
 func (t *tqCounter) Lease(maxTasks int, queueName string, leaseTime time.Duration) ([]*tq.Task, error) {
 	tasks, err := t.tq.Lease(maxTasks, queueName, leaseTime)
-	t.c.Lease.up(err)
+	t.c.Lease.up(t.ns, err)
 	return tasks, err
 }
 
 func (t *tqCounter) LeaseByTag(maxTasks int, queueName string, leaseTime time.Duration, tag string) ([]*tq.Task, error) {
 	tasks, err := t.tq.LeaseByTag(maxTasks, queueName, leaseTime, tag)
-	t.c.LeaseByTag.up(err)
+	t.c.LeaseByTag.up(t.ns, err)
 	return tasks, err
 }
 
 func (t *tqCounter) ModifyLease(task *tq.Task, queueName string, leaseTime time.Duration) error {
-	return t.c.ModifyLease.up(t.tq.ModifyLease(task, queueName, leaseTime))
+	return t.c.ModifyLease.up(t.ns, t.tq.ModifyLease(task, queueName, leaseTime))
 }
 
 func (t *tqCounter) Purge(queueName string) error {
-	return t.c.Purge.up(t.tq.Purge(queueName))
+	return t.c.Purge.up(t.ns, t.tq.Purge(queueName))
 }
 
 func (t *tqCounter) Stats(queueNames []string, cb tq.RawStatsCB) error {
-	return t.c.Stats.up(t.tq.Stats(queueNames, cb))
+	return t.c.Stats.up(t.ns, t.tq.Stats(queueNames, cb))
 }
 
 func (t *tqCounter) Constraints() tq.Constraints {
@@ -85,6 +97,6 @@ func (t *tqCounter) GetTestable() tq.Testable {
 func FilterTQ(c context.Context) (context.Context, *TQCounter) {
 	state := &TQCounter{}
 	return tq.AddRawFilters(c, func(ic context.Context, tq tq.RawInterface) tq.RawInterface {
-		return &tqCounter{state, tq}
+		return &tqCounter{state, info.GetNamespace(ic), tq}
 	}), state
 }
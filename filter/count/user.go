@@ -15,6 +15,7 @@
 package count
 
 import (
+	"go.chromium.org/gae/service/info"
 	"go.chromium.org/gae/service/user"
 	"golang.org/x/net/context"
 )
@@ -31,7 +32,8 @@ type UserCounter struct {
 }
 
 type userCounter struct {
-	c *UserCounter
+	c  *UserCounter
+	ns string
 
 	u user.RawInterface
 }
@@ -39,38 +41,38 @@ type userCounter struct {
 var _ user.RawInterface = (*userCounter)(nil)
 
 func (u *userCounter) Current() *user.User {
-	u.c.Current.up()
+	u.c.Current.up(u.ns)
 	return u.u.Current()
 }
 
 func (u *userCounter) CurrentOAuth(scopes ...string) (*user.User, error) {
 	ret, err := u.u.CurrentOAuth(scopes...)
-	return ret, u.c.CurrentOAuth.up(err)
+	return ret, u.c.CurrentOAuth.up(u.ns, err)
 }
 
 func (u *userCounter) IsAdmin() bool {
-	u.c.IsAdmin.up()
+	u.c.IsAdmin.up(u.ns)
 	return u.u.IsAdmin()
 }
 
 func (u *userCounter) LoginURL(dest string) (string, error) {
 	ret, err := u.u.LoginURL(dest)
-	return ret, u.c.LoginURL.up(err)
+	return ret, u.c.LoginURL.up(u.ns, err)
 }
 
 func (u *userCounter) LoginURLFederated(dest, identity string) (string, error) {
 	ret, err := u.u.LoginURLFederated(dest, identity)
-	return ret, u.c.LoginURLFederated.up(err)
+	return ret, u.c.LoginURLFederated.up(u.ns, err)
 }
 
 func (u *userCounter) LogoutURL(dest string) (string, error) {
 	ret, err := u.u.LogoutURL(dest)
-	return ret, u.c.LogoutURL.up(err)
+	return ret, u.c.LogoutURL.up(u.ns, err)
 }
 
 func (u *userCounter) OAuthConsumerKey() (string, error) {
 	ret, err := u.u.OAuthConsumerKey()
-	return ret, u.c.OAuthConsumerKey.up(err)
+	return ret, u.c.OAuthConsumerKey.up(u.ns, err)
 }
 
 func (u *userCounter) GetTestable() user.Testable {
@@ -81,6 +83,6 @@ func (u *userCounter) GetTestable() user.Testable {
 func FilterUser(c context.Context) (context.Context, *UserCounter) {
 	state := &UserCounter{}
 	return user.AddFilters(c, func(ic context.Context, u user.RawInterface) user.RawInterface {
-		return &userCounter{state, u}
+		return &userCounter{state, info.GetNamespace(ic), u}
 	}), state
 }
@@ -22,6 +22,8 @@ import (
 	"sync"
 
 	"golang.org/x/net/context"
+
+	ds "go.chromium.org/gae/service/datastore"
 )
 
 // BreakFeatureCallback can be used to break features at the time of the call.
@@ -36,6 +38,100 @@ import (
 // synchronization if necessary.
 type BreakFeatureCallback func(ctx context.Context, feature string) error
 
+// CallInfo describes a call to a feature, or, for batch (Multi) methods, a
+// single item of such a call. It's passed to BreakIfCallback.
+//
+// Only the fields relevant to the called feature are populated.
+type CallInfo struct {
+	// Feature is the name of the called method (e.g. "PutMulti").
+	Feature string
+
+	// Index is the index of the item within the batch method call. It's 0 for
+	// non-batch methods.
+	Index int
+
+	// Key is the datastore key of the item, for datastore batch methods.
+	Key *ds.Key
+
+	// Kind is the datastore kind of the item's Key, or the kind of the query for
+	// datastore Run and Count.
+	Kind string
+
+	// MemcacheKey is the key of the item, for memcache batch methods.
+	MemcacheKey string
+
+	// QueueName is the name of the queue, for taskqueue methods. It's passed
+	// as-is, so it's "" for calls to the default queue.
+	QueueName string
+
+	// TaskName is the name of the task, for taskqueue batch methods.
+	TaskName string
+}
+
+// BreakIfCallback can be used to break features (or individual items of
+// batch features) based on the arguments of the call.
+//
+// If it returns an error, the call (or the item) fails with this error. If
+// the error is ErrUseDefault, the default error passed to the Filter function
+// is used instead. If it returns nil, the call (or the item) is performed as
+// usual.
+//
+// The callback will be called often and concurrently. Provide your own
+// synchronization if necessary. For a single batch call, though, it's called
+// sequentially for each item, in order.
+type BreakIfCallback func(ctx context.Context, info CallInfo) error
+
+// ForKinds returns a BreakIfCallback which fails datastore calls and items
+// whose kind is one of kinds with err. If err is nil, the default error is
+// used.
+func ForKinds(err error, kinds ...string) BreakIfCallback {
+	if err == nil {
+		err = ErrUseDefault
+	}
+	set := make(map[string]struct{}, len(kinds))
+	for _, k := range kinds {
+		set[k] = struct{}{}
+	}
+	return func(_ context.Context, info CallInfo) error {
+		if _, ok := set[info.Kind]; ok {
+			return err
+		}
+		return nil
+	}
+}
+
+// ForKeyPrefix returns a BreakIfCallback which fails memcache items whose key
+// starts with prefix with err. If err is nil, the default error is used.
+func ForKeyPrefix(err error, prefix string) BreakIfCallback {
+	if err == nil {
+		err = ErrUseDefault
+	}
+	return func(_ context.Context, info CallInfo) error {
+		if strings.HasPrefix(info.MemcacheKey, prefix) {
+			return err
+		}
+		return nil
+	}
+}
+
+// ForQueues returns a BreakIfCallback which fails taskqueue calls and items
+// targeting one of queues with err. If err is nil, the default error is used.
+func ForQueues(err error, queues ...string) BreakIfCallback {
+	if err == nil {
+		err = ErrUseDefault
+	}
+	set := make(map[string]struct{}, len(queues))
+	for _, q := range queues {
+		set[q] = struct{}{}
+	}
+	return func(_ context.Context, info CallInfo) error {
+		if _, ok := set[info.QueueName]; ok {
+			return err
+		}
+		return nil
+	}
+}
+
 // FeatureBreaker is the state-access interface for all Filter* functions in
 // this package.  A feature is the Name of some method on the filtered service.
 //
@@ -92,17 +188,38 @@ type FeatureBreaker interface {
 	// using callbacks.
 	BreakFeaturesWithCallback(cb BreakFeatureCallback, feature ...string)
 
-	// UnbreakFeatures is the inverse of BreakFeatures/BreakFeaturesWithCallback,
-	// and will return the named features back to their original functionality.
+	// BreakIf is like BreakFeaturesWithCallback, except that the callback
+	// receives the arguments of the call, and so can decide to break only some
+	// calls.
+	//
+	// For batch (Multi) methods, the callback is evaluated for each item
+	// separately. Items for which it returns an error fail with that error,
+	// and the rest are passed to the underlying service as usual, so that
+	// a single call may partially fail (e.g. return a MultiError mixing
+	// successes and failures to the user).
+	//
+	// See ForKinds, ForKeyPrefix and ForQueues for common callbacks.
+	BreakIf(cb BreakIfCallback, feature ...string)
+
+	// UnbreakFeatures is the inverse of BreakFeatures/BreakFeaturesWithCallback/
+	// BreakIf, and will return the named features back to their original
+	// functionality.
 	UnbreakFeatures(feature ...string)
 }
 
-// errUseDefault is never returned but used as an indicator to use defaultError.
-var errUseDefault = errors.New("use default error")
+// ErrUseDefault can be returned by BreakIfCallback to fail with the default
+// error passed to the Filter function.
+var ErrUseDefault = errors.New("use default error")
+
+// breakage is how a single feature is broken. Exactly one of its fields is set.
+type breakage struct {
+	cb   BreakFeatureCallback
+	pred BreakIfCallback
+}
 
 type state struct {
 	l      sync.RWMutex
-	broken map[string]BreakFeatureCallback
+	broken map[string]breakage
 
 	// defaultError is the default error to return when you call
 	// BreakFeatures(nil, ...). If this is unset and the user calls BreakFeatures
@@ -112,14 +229,14 @@ type state struct {
 
 func newState(dflt error) *state {
 	return &state{
-		broken:       map[string]BreakFeatureCallback{},
+		broken:       map[string]breakage{},
 		defaultError: dflt,
 	}
 }
 
 func (s *state) BreakFeatures(err error, feature ...string) {
 	if err == nil {
-		err = errUseDefault
+		err = ErrUseDefault
 	}
 	s.BreakFeaturesWithCallback(
 		func(context.Context, string) error { return err },
@@ -127,6 +244,14 @@ func (s *state) BreakFeatures(err error, feature ...string) {
 }
 
 func (s *state) BreakFeaturesWithCallback(cb BreakFeatureCallback, feature ...string) {
+	s.setBroken(breakage{cb: cb}, feature)
+}
+
+func (s *state) BreakIf(cb BreakIfCallback, feature ...string) {
+	s.setBroken(breakage{pred: cb}, feature)
+}
+
+func (s *state) setBroken(b breakage, feature []string) {
 	for _, f := range feature {
 		if f == "RunInTransaction" {
 			panic("break BeginTransaction or CommitTransaction instead of RunInTransaction")
@@ -135,7 +260,7 @@ func (s *state) BreakFeaturesWithCallback(cb BreakFeatureCallback, feature ...st
 	s.l.Lock()
 	defer s.l.Unlock()
 	for _, f := range feature {
-		s.broken[f] = cb
+		s.broken[f] = b
 	}
 }
 
@@ -147,36 +272,114 @@ func (s *state) UnbreakFeatures(feature ...string) {
 	}
 }
 
+// callerFeature returns the name of the feature (method) which called the
+// state method calling callerFeature.
+func callerFeature() string {
+	pc, _, _, _ := runtime.Caller(2)
+	fullName := runtime.FuncForPC(pc).Name()
+	fullNameParts := strings.Split(fullName, ".")
+	return fullNameParts[len(fullNameParts)-1]
+}
+
 func (s *state) run(c context.Context, f func() error) error {
 	if s.noBrokenFeatures() {
 		return f()
 	}
+	return s.apply(c, callerFeature(), CallInfo{}, f)
+}
 
-	pc, _, _, _ := runtime.Caller(1)
-	fullName := runtime.FuncForPC(pc).Name()
-	fullNameParts := strings.Split(fullName, ".")
-	name := fullNameParts[len(fullNameParts)-1]
+// runInfo is like run, but passes info to the BreakIf callback, if any.
+func (s *state) runInfo(c context.Context, info CallInfo, f func() error) error {
+	if s.noBrokenFeatures() {
+		return f()
+	}
+	return s.apply(c, callerFeature(), info, f)
+}
+
+// runMulti is like run, but for batch methods operating on n items.
+//
+// If the feature is broken with BreakIf, the callback is evaluated for each
+// item (described by info(i)) in order, and f receives the resulting per-item
+// errors. Otherwise f receives nil, meaning that all items should proceed.
+func (s *state) runMulti(c context.Context, n int, info func(i int) CallInfo, f func(errs []error) error) error {
+	if s.noBrokenFeatures() {
+		return f(nil)
+	}
+	name := callerFeature()
 
 	s.l.RLock()
-	cb := s.broken[name]
-	dflt := s.defaultError
+	b := s.broken[name]
 	s.l.RUnlock()
 
-	if cb == nil {
-		return f()
+	if b.pred == nil {
+		return s.apply(c, name, CallInfo{}, func() error { return f(nil) })
 	}
 
-	switch err := cb(c, name); {
-	case err == nil: // the callback decided not to break the feature
+	errs := make([]error, n)
+	for i := range errs {
+		ci := info(i)
+		ci.Feature = name
+		ci.Index = i
+		errs[i] = s.resolve(name, b.pred(c, ci))
+	}
+	return f(errs)
+}
+
+func (s *state) apply(c context.Context, name string, info CallInfo, f func() error) error {
+	s.l.RLock()
+	b := s.broken[name]
+	s.l.RUnlock()
+
+	var err error
+	switch {
+	case b.cb != nil:
+		err = b.cb(c, name)
+	case b.pred != nil:
+		info.Feature = name
+		err = b.pred(c, info)
+	}
+	if err == nil {
+		// the callback decided not to break the feature
 		return f()
-	case err == errUseDefault:
-		if dflt != nil {
-			return dflt
-		}
-		return fmt.Errorf("feature %q is broken", name)
-	default:
+	}
+	return s.resolve(name, err)
+}
+
+// resolve replaces ErrUseDefault with the actual default error for feature
+// name.
+func (s *state) resolve(name string, err error) error {
+	if err != ErrUseDefault {
 		return err
 	}
+	s.l.RLock()
+	dflt := s.defaultError
+	s.l.RUnlock()
+	if dflt != nil {
+		return dflt
+	}
+	return fmt.Errorf("feature %q is broken", name)
+}
+
+// passedItems returns the indexes of the items whose errs are nil. It returns
+// nil if all items passed (including when errs is nil).
+func passedItems(errs []error) []int {
+	failed := false
+	for _, err := range errs {
+		if err != nil {
+			failed = true
+			break
+		}
+	}
+	if !failed {
+		return nil
+	}
+	ret := make([]int, 0, len(errs))
+	for i, err := range errs {
+		if err == nil {
+			ret = append(ret, i)
+		}
+	}
+	return ret
 }
 
 func (s *state) noBrokenFeatures() bool {
@@ -15,10 +15,16 @@
 package featureBreaker
 
 import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"go.chromium.org/gae/impl/memory"
 	ds "go.chromium.org/gae/service/datastore"
+	mc "go.chromium.org/gae/service/memcache"
+	tq "go.chromium.org/gae/service/taskqueue"
 	"go.chromium.org/luci/common/errors"
 
 	"golang.org/x/net/context"
@@ -122,3 +128,217 @@ func TestBrokenFeatures(t *testing.T) {
 		})
 	})
 }
+
+func TestBreakIf(t *testing.T) {
+	t.Parallel()
+
+	e := errors.New("default err")
+	broken := errors.New("broken")
+
+	Convey("BreakIf", t, func() {
+		c := memory.Use(context.Background())
+
+		Convey("Can break ds by kind", func() {
+			c, bf := FilterRDS(c, e)
+			ds.GetTestable(c).Consistent(true)
+
+			mkVals := func(kinds ...string) []ds.PropertyMap {
+				vals := make([]ds.PropertyMap, len(kinds))
+				for i, kind := range kinds {
+					vals[i] = ds.PropertyMap{
+						"$key": ds.MkPropertyNI(ds.NewKey(c, kind, "", int64(i+1), nil)),
+					}
+				}
+				return vals
+			}
+
+			bf.BreakIf(ForKinds(broken, "AuditLog"), "PutMulti", "GetMulti", "DeleteMulti", "Run", "Count")
+
+			Convey("batch methods fail per item", func() {
+				vals := mkVals("Thing", "AuditLog", "Thing")
+				So(ds.Put(c, vals), ShouldResemble, errors.MultiError{nil, broken, nil})
+
+				bf.UnbreakFeatures("PutMulti")
+				So(ds.Get(c, vals), ShouldResemble, errors.MultiError{nil, broken, nil})
+
+				bf.UnbreakFeatures("GetMulti")
+				So(ds.Get(c, vals), ShouldResemble, errors.MultiError{nil, ds.ErrNoSuchEntity, nil})
+
+				So(ds.Delete(c, vals), ShouldResemble, errors.MultiError{nil, broken, nil})
+				So(ds.Get(c, vals), ShouldResemble, errors.MultiError{
+					ds.ErrNoSuchEntity, ds.ErrNoSuchEntity, ds.ErrNoSuchEntity})
+			})
+
+			Convey("when all items fail, the underlying service isn't called", func() {
+				So(ds.Put(c, mkVals("AuditLog")), ShouldResemble, errors.MultiError{broken})
+			})
+
+			Convey("queries use the query kind", func() {
+				So(ds.Run(c, ds.NewQuery("AuditLog"), func(ds.PropertyMap) {}), ShouldEqual, broken)
+				_, err := ds.Count(c, ds.NewQuery("AuditLog"))
+				So(err, ShouldEqual, broken)
+
+				So(ds.Run(c, ds.NewQuery("Thing"), func(ds.PropertyMap) {}), ShouldBeNil)
+			})
+
+			Convey("ErrUseDefault uses the default error", func() {
+				bf.BreakIf(ForKinds(nil, "AuditLog"), "PutMulti")
+				So(ds.Put(c, mkVals("AuditLog", "Thing")), ShouldResemble, errors.MultiError{e, nil})
+			})
+
+			Convey("BreakFeatures replaces BreakIf", func() {
+				bf.BreakFeatures(nil, "PutMulti")
+				So(ds.Put(c, mkVals("Thing")), ShouldEqual, e)
+			})
+		})
+
+		Convey("Can break memcache by key prefix", func() {
+			c, bf := FilterMC(c, nil)
+			bf.BreakIf(ForKeyPrefix(broken, "sess:"), "SetMulti", "GetMulti")
+
+			items := []mc.Item{
+				mc.NewItem(c, "sess:1").SetValue([]byte("a")),
+				mc.NewItem(c, "user:1").SetValue([]byte("b")),
+				mc.NewItem(c, "sess:2").SetValue([]byte("c")),
+			}
+			So(mc.Set(c, items...), ShouldResemble, errors.MultiError{broken, nil, broken})
+
+			got := []mc.Item{
+				mc.NewItem(c, "sess:1"),
+				mc.NewItem(c, "user:1"),
+				mc.NewItem(c, "nope"),
+			}
+			So(mc.Get(c, got...), ShouldResemble, errors.MultiError{broken, nil, mc.ErrCacheMiss})
+			So(got[1].Value(), ShouldResemble, []byte("b"))
+
+			Convey("and reports nil items for broken and missing keys", func() {
+				var gotItems []mc.Item
+				var gotErrs []error
+				err := mc.Raw(c).GetMulti([]string{"sess:1", "user:1", "nope"}, func(item mc.Item, err error) {
+					gotItems = append(gotItems, item)
+					gotErrs = append(gotErrs, err)
+				})
+				So(err, ShouldBeNil)
+				So(gotErrs, ShouldResemble, []error{broken, nil, mc.ErrCacheMiss})
+				So(gotItems[0], ShouldBeNil)
+				So(gotItems[1].Value(), ShouldResemble, []byte("b"))
+				So(gotItems[2], ShouldBeNil)
+			})
+		})
+
+		Convey("Can break taskqueue by queue", func() {
+			c, bf := FilterTQ(c, nil)
+			tq.GetTestable(c).CreateQueue("bad")
+			bf.BreakIf(ForQueues(broken, "bad"), "AddMulti")
+
+			So(tq.Add(c, "bad", &tq.Task{Name: "a"}), ShouldEqual, broken)
+			So(tq.Add(c, "", &tq.Task{Name: "a"}), ShouldBeNil)
+
+			Convey("and by task name", func() {
+				bf.BreakIf(func(_ context.Context, info CallInfo) error {
+					if strings.HasPrefix(info.TaskName, "x") {
+						return broken
+					}
+					return nil
+				}, "AddMulti")
+
+				So(tq.Add(c, "", &tq.Task{Name: "b"}, &tq.Task{Name: "xc"}, &tq.Task{Name: "d"}),
+					ShouldResemble, errors.MultiError{nil, broken, nil})
+				So(tq.GetTestable(c).GetScheduledTasks()["default"], ShouldContainKey, "d")
+				So(tq.GetTestable(c).GetScheduledTasks()["default"], ShouldNotContainKey, "xc")
+
+				Convey("and reports nil tasks for broken entries", func() {
+					var added []*tq.Task
+					var addErrs []error
+					err := tq.Raw(c).AddMulti([]*tq.Task{{Name: "e"}, {Name: "xf"}}, "", func(task *tq.Task, err error) {
+						added = append(added, task)
+						addErrs = append(addErrs, err)
+					})
+					So(err, ShouldBeNil)
+					So(addErrs, ShouldResemble, []error{nil, broken})
+					So(added[0].Name, ShouldEqual, "e")
+					So(added[1], ShouldBeNil)
+				})
+			})
+		})
+
+		Convey("Predicates are evaluated deterministically", func() {
+			c, bf := FilterRDS(c, nil)
+
+			var seen []CallInfo
+			bf.BreakIf(func(_ context.Context, info CallInfo) error {
+				seen = append(seen, info)
+				if info.Index%2 == 1 {
+					return broken
+				}
+				return nil
+			}, "PutMulti")
+
+			vals := make([]ds.PropertyMap, 5)
+			for i := range vals {
+				vals[i] = ds.PropertyMap{
+					"$key": ds.MkPropertyNI(ds.NewKey(c, "Kind", "", int64(i+1), nil)),
+				}
+			}
+
+			for round := 0; round < 3; round++ {
+				seen = nil
+				So(ds.Put(c, vals), ShouldResemble, errors.MultiError{nil, broken, nil, broken, nil})
+				So(seen, ShouldHaveLength, len(vals))
+				for i, info := range seen {
+					So(info.Feature, ShouldEqual, "PutMulti")
+					So(info.Index, ShouldEqual, i)
+					So(info.Kind, ShouldEqual, "Kind")
+					So(info.Key, ShouldResemble, ds.GetMetaDefault(vals[i], "key", nil))
+				}
+			}
+		})
+
+		Convey("Predicates are goroutine-safe", func() {
+			c, bf := FilterRDS(c, nil)
+
+			calls := int32(0)
+			bf.BreakIf(func(_ context.Context, info CallInfo) error {
+				atomic.AddInt32(&calls, 1)
+				if info.Key.IntID()%2 == 0 {
+					return broken
+				}
+				return nil
+			}, "PutMulti")
+
+			const workers = 8
+			const perWorker = 10
+
+			failures := int32(0)
+			wg := sync.WaitGroup{}
+			for w := 0; w < workers; w++ {
+				w := w
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					vals := make([]ds.PropertyMap, perWorker)
+					for i := range vals {
+						vals[i] = ds.PropertyMap{
+							"$key": ds.MkPropertyNI(ds.NewKey(c, "Kind", "", int64(w*perWorker+i+1), nil)),
+						}
+					}
+					if me, ok := ds.Put(c, vals).(errors.MultiError); ok {
+						for _, err := range me {
+							if err != nil {
+								atomic.AddInt32(&failures, 1)
+							}
+						}
+					}
+
+					// Concurrently rebreaking the feature must be safe, too.
+					bf.BreakFeaturesWithCallback(func(context.Context, string) error { return nil }, fmt.Sprintf("Feature%d", w))
+				}()
+			}
+			wg.Wait()
+
+			So(atomic.LoadInt32(&calls), ShouldEqual, workers*perWorker)
+			So(atomic.LoadInt32(&failures), ShouldEqual, workers*perWorker/2)
+		})
+	})
+}
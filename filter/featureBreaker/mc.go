@@ -31,35 +31,93 @@ func (m *mcState) GetMulti(keys []string, cb mc.RawItemCB) error {
 	if len(keys) == 0 {
 		return nil
 	}
-	return m.run(m.c, func() error { return m.RawInterface.GetMulti(keys, cb) })
+	return m.runMulti(m.c, len(keys), mcKeyInfo(keys), func(errs []error) error {
+		idxs := passedItems(errs)
+		if idxs == nil {
+			return m.RawInterface.GetMulti(keys, cb)
+		}
+
+		// Memcache callbacks are positional, so buffer the results and report
+		// them in order.
+		items := make([]mc.Item, len(keys))
+		if len(idxs) > 0 {
+			j := 0
+			err := m.RawInterface.GetMulti(pickStrings(keys, idxs), func(item mc.Item, err error) {
+				items[idxs[j]], errs[idxs[j]] = item, err
+				j++
+			})
+			if err != nil {
+				return err
+			}
+		}
+		for i, item := range items {
+			if errs[i] != nil {
+				item = nil
+			}
+			cb(item, errs[i])
+		}
+		return nil
+	})
 }
 
 func (m *mcState) AddMulti(items []mc.Item, cb mc.RawCB) error {
 	if len(items) == 0 {
 		return nil
 	}
-	return m.run(m.c, func() error { return m.RawInterface.AddMulti(items, cb) })
+	return m.runMulti(m.c, len(items), mcItemInfo(items), func(errs []error) error {
+		idxs := passedItems(errs)
+		if idxs == nil {
+			return m.RawInterface.AddMulti(items, cb)
+		}
+		return runPartial(errs, idxs, cb, func(sub mc.RawCB) error {
+			return m.RawInterface.AddMulti(pickItems(items, idxs), sub)
+		})
+	})
 }
 
 func (m *mcState) SetMulti(items []mc.Item, cb mc.RawCB) error {
 	if len(items) == 0 {
 		return nil
 	}
-	return m.run(m.c, func() error { return m.RawInterface.SetMulti(items, cb) })
+	return m.runMulti(m.c, len(items), mcItemInfo(items), func(errs []error) error {
+		idxs := passedItems(errs)
+		if idxs == nil {
+			return m.RawInterface.SetMulti(items, cb)
+		}
+		return runPartial(errs, idxs, cb, func(sub mc.RawCB) error {
+			return m.RawInterface.SetMulti(pickItems(items, idxs), sub)
+		})
+	})
 }
 
 func (m *mcState) DeleteMulti(keys []string, cb mc.RawCB) error {
 	if len(keys) == 0 {
 		return nil
 	}
-	return m.run(m.c, func() error { return m.RawInterface.DeleteMulti(keys, cb) })
+	return m.runMulti(m.c, len(keys), mcKeyInfo(keys), func(errs []error) error {
+		idxs := passedItems(errs)
+		if idxs == nil {
+			return m.RawInterface.DeleteMulti(keys, cb)
+		}
+		return runPartial(errs, idxs, cb, func(sub mc.RawCB) error {
+			return m.RawInterface.DeleteMulti(pickStrings(keys, idxs), sub)
+		})
+	})
 }
 
 func (m *mcState) CompareAndSwapMulti(items []mc.Item, cb mc.RawCB) error {
 	if len(items) == 0 {
 		return nil
 	}
-	return m.run(m.c, func() error { return m.RawInterface.CompareAndSwapMulti(items, cb) })
+	return m.runMulti(m.c, len(items), mcItemInfo(items), func(errs []error) error {
+		idxs := passedItems(errs)
+		if idxs == nil {
+			return m.RawInterface.CompareAndSwapMulti(items, cb)
+		}
+		return runPartial(errs, idxs, cb, func(sub mc.RawCB) error {
+			return m.RawInterface.CompareAndSwapMulti(pickItems(items, idxs), sub)
+		})
+	})
 }
 
 func (m *mcState) Flush() error {
@@ -74,6 +132,49 @@ func (m *mcState) Stats() (ret *mc.Statistics, err error) {
 	return
 }
 
+func mcKeyInfo(keys []string) func(int) CallInfo {
+	return func(i int) CallInfo { return CallInfo{MemcacheKey: keys[i]} }
+}
+
+func mcItemInfo(items []mc.Item) func(int) CallInfo {
+	return func(i int) CallInfo { return CallInfo{MemcacheKey: items[i].Key()} }
+}
+
+func pickStrings(strs []string, idxs []int) []string {
+	ret := make([]string, len(idxs))
+	for j, i := range idxs {
+		ret[j] = strs[i]
+	}
+	return ret
+}
+
+func pickItems(items []mc.Item, idxs []int) []mc.Item {
+	ret := make([]mc.Item, len(idxs))
+	for j, i := range idxs {
+		ret[j] = items[i]
+	}
+	return ret
+}
+
+// runPartial runs f, which should operate on just the items in idxs, and then
+// reports the combined per-item results in errs to cb, in order.
+func runPartial(errs []error, idxs []int, cb mc.RawCB, f func(sub mc.RawCB) error) error {
+	if len(idxs) > 0 {
+		j := 0
+		err := f(func(err error) {
+			errs[idxs[j]] = err
+			j++
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, err := range errs {
+		cb(err)
+	}
+	return nil
+}
+
 // FilterMC installs a featureBreaker mc filter in the context.
 func FilterMC(c context.Context, defaultError error) (context.Context, FeatureBreaker) {
 	state := newState(defaultError)
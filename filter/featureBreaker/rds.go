@@ -44,8 +44,24 @@ func (r *dsState) AllocateIDs(keys []*ds.Key, cb ds.NewKeyCB) error {
 	if len(keys) == 0 {
 		return nil
 	}
-	return r.run(r.c, func() error {
-		return r.rds.AllocateIDs(keys, cb)
+	return r.runMulti(r.c, len(keys), keyInfo(keys), func(errs []error) error {
+		idxs := passedItems(errs)
+		if idxs == nil {
+			return r.rds.AllocateIDs(keys, cb)
+		}
+		for i, err := range errs {
+			if err != nil {
+				if err := cb(i, nil, err); err != nil {
+					return err
+				}
+			}
+		}
+		if len(idxs) == 0 {
+			return nil
+		}
+		return r.rds.AllocateIDs(pickKeys(keys, idxs), func(j int, key *ds.Key, err error) error {
+			return cb(idxs[j], key, err)
+		})
 	})
 }
 
@@ -59,14 +75,14 @@ func (r *dsState) DecodeCursor(s string) (ds.Cursor, error) {
 }
 
 func (r *dsState) Run(q *ds.FinalizedQuery, cb ds.RawRunCB) error {
-	return r.run(r.c, func() error {
+	return r.runInfo(r.c, CallInfo{Kind: q.Kind()}, func() error {
 		return r.rds.Run(q, cb)
 	})
 }
 
 func (r *dsState) Count(q *ds.FinalizedQuery) (int64, error) {
 	count := int64(0)
-	err := r.run(r.c, func() (err error) {
+	err := r.runInfo(r.c, CallInfo{Kind: q.Kind()}, func() (err error) {
 		count, err = r.rds.Count(q)
 		return
 	})
@@ -115,8 +131,24 @@ func (r *dsState) DeleteMulti(keys []*ds.Key, cb ds.DeleteMultiCB) error {
 	if len(keys) == 0 {
 		return nil
 	}
-	return r.run(r.c, func() error {
-		return r.rds.DeleteMulti(keys, cb)
+	return r.runMulti(r.c, len(keys), keyInfo(keys), func(errs []error) error {
+		idxs := passedItems(errs)
+		if idxs == nil {
+			return r.rds.DeleteMulti(keys, cb)
+		}
+		for i, err := range errs {
+			if err != nil {
+				if err := cb(i, err); err != nil {
+					return err
+				}
+			}
+		}
+		if len(idxs) == 0 {
+			return nil
+		}
+		return r.rds.DeleteMulti(pickKeys(keys, idxs), func(j int, err error) error {
+			return cb(idxs[j], err)
+		})
 	})
 }
 
@@ -124,8 +156,33 @@ func (r *dsState) GetMulti(keys []*ds.Key, meta ds.MultiMetaGetter, cb ds.GetMul
 	if len(keys) == 0 {
 		return nil
 	}
-	return r.run(r.c, func() error {
-		return r.rds.GetMulti(keys, meta, cb)
+	return r.runMulti(r.c, len(keys), keyInfo(keys), func(errs []error) error {
+		idxs := passedItems(errs)
+		if idxs == nil {
+			return r.rds.GetMulti(keys, meta, cb)
+		}
+		for i, err := range errs {
+			if err != nil {
+				if err := cb(i, nil, err); err != nil {
+					return err
+				}
+			}
+		}
+		if len(idxs) == 0 {
+			return nil
+		}
+		subMeta := ds.MultiMetaGetter(nil)
+		if meta != nil {
+			subMeta = make(ds.MultiMetaGetter, len(idxs))
+			for j, i := range idxs {
+				if i < len(meta) {
+					subMeta[j] = meta[i]
+				}
+			}
+		}
+		return r.rds.GetMulti(pickKeys(keys, idxs), subMeta, func(j int, pm ds.PropertyMap, err error) error {
+			return cb(idxs[j], pm, err)
+		})
 	})
 }
 
@@ -133,8 +190,28 @@ func (r *dsState) PutMulti(keys []*ds.Key, vals []ds.PropertyMap, cb ds.NewKeyCB
 	if len(keys) == 0 {
 		return nil
 	}
-	return r.run(r.c, func() (err error) {
-		return r.rds.PutMulti(keys, vals, cb)
+	return r.runMulti(r.c, len(keys), keyInfo(keys), func(errs []error) error {
+		idxs := passedItems(errs)
+		if idxs == nil {
+			return r.rds.PutMulti(keys, vals, cb)
+		}
+		for i, err := range errs {
+			if err != nil {
+				if err := cb(i, nil, err); err != nil {
+					return err
+				}
+			}
+		}
+		if len(idxs) == 0 {
+			return nil
+		}
+		subVals := make([]ds.PropertyMap, len(idxs))
+		for j, i := range idxs {
+			subVals[j] = vals[i]
+		}
+		return r.rds.PutMulti(pickKeys(keys, idxs), subVals, func(j int, key *ds.Key, err error) error {
+			return cb(idxs[j], key, err)
+		})
 	})
 }
 
@@ -152,6 +229,22 @@ func (r *dsState) GetTestable() ds.Testable {
 	return r.rds.GetTestable()
 }
 
+// keyInfo returns a function which describes the i'th key of a datastore
+// batch call.
+func keyInfo(keys []*ds.Key) func(int) CallInfo {
+	return func(i int) CallInfo {
+		return CallInfo{Key: keys[i], Kind: keys[i].Kind()}
+	}
+}
+
+func pickKeys(keys []*ds.Key, idxs []int) []*ds.Key {
+	ret := make([]*ds.Key, len(idxs))
+	for j, i := range idxs {
+		ret[j] = keys[i]
+	}
+	return ret
+}
+
 // FilterRDS installs a featureBreaker datastore filter in the context.
 func FilterRDS(c context.Context, defaultError error) (context.Context, FeatureBreaker) {
 	state := newState(defaultError)
@@ -35,18 +35,60 @@ func (t *tqState) AddMulti(tasks []*tq.Task, queueName string, cb tq.RawTaskCB)
 	if len(tasks) == 0 {
 		return nil
 	}
-	return t.run(t.c, func() (err error) { return t.tq.AddMulti(tasks, queueName, cb) })
+	return t.runMulti(t.c, len(tasks), taskInfo(tasks, queueName), func(errs []error) error {
+		idxs := passedItems(errs)
+		if idxs == nil {
+			return t.tq.AddMulti(tasks, queueName, cb)
+		}
+
+		// AddMulti callbacks are positional, so buffer the results and report
+		// them in order.
+		added := make([]*tq.Task, len(tasks))
+		if len(idxs) > 0 {
+			j := 0
+			err := t.tq.AddMulti(pickTasks(tasks, idxs), queueName, func(task *tq.Task, err error) {
+				added[idxs[j]], errs[idxs[j]] = task, err
+				j++
+			})
+			if err != nil {
+				return err
+			}
+		}
+		for i, task := range added {
+			if errs[i] != nil {
+				task = nil
+			}
+			cb(task, errs[i])
+		}
+		return nil
+	})
 }
 
 func (t *tqState) DeleteMulti(tasks []*tq.Task, queueName string, cb tq.RawCB) error {
 	if len(tasks) == 0 {
 		return nil
 	}
-	return t.run(t.c, func() error { return t.tq.DeleteMulti(tasks, queueName, cb) })
+	return t.runMulti(t.c, len(tasks), taskInfo(tasks, queueName), func(errs []error) error {
+		idxs := passedItems(errs)
+		if idxs == nil {
+			return t.tq.DeleteMulti(tasks, queueName, cb)
+		}
+		for i, err := range errs {
+			if err != nil {
+				cb(i, err)
+			}
+		}
+		if len(idxs) == 0 {
+			return nil
+		}
+		return t.tq.DeleteMulti(pickTasks(tasks, idxs), queueName, func(j int, err error) {
+			cb(idxs[j], err)
+		})
+	})
 }
 
 func (t *tqState) Lease(maxTasks int, queueName string, leaseTime time.Duration) (tasks []*tq.Task, err error) {
-	err = t.run(t.c, func() (err error) {
+	err = t.runInfo(t.c, CallInfo{QueueName: queueName}, func() (err error) {
 		tasks, err = t.tq.Lease(maxTasks, queueName, leaseTime)
 		return
 	})
@@ -57,7 +99,7 @@ func (t *tqState) Lease(maxTasks int, queueName string, leaseTime time.Duration)
 }
 
 func (t *tqState) LeaseByTag(maxTasks int, queueName string, leaseTime time.Duration, tag string) (tasks []*tq.Task, err error) {
-	err = t.run(t.c, func() (err error) {
+	err = t.runInfo(t.c, CallInfo{QueueName: queueName}, func() (err error) {
 		tasks, err = t.tq.LeaseByTag(maxTasks, queueName, leaseTime, tag)
 		return
 	})
@@ -68,11 +110,13 @@ func (t *tqState) LeaseByTag(maxTasks int, queueName string, leaseTime time.Dura
 }
 
 func (t *tqState) ModifyLease(task *tq.Task, queueName string, leaseTime time.Duration) error {
-	return t.run(t.c, func() error { return t.tq.ModifyLease(task, queueName, leaseTime) })
+	return t.runInfo(t.c, CallInfo{QueueName: queueName, TaskName: task.Name}, func() error {
+		return t.tq.ModifyLease(task, queueName, leaseTime)
+	})
 }
 
 func (t *tqState) Purge(queueName string) error {
-	return t.run(t.c, func() error { return t.tq.Purge(queueName) })
+	return t.runInfo(t.c, CallInfo{QueueName: queueName}, func() error { return t.tq.Purge(queueName) })
 }
 
 func (t *tqState) Stats(queueNames []string, cb tq.RawStatsCB) error {
@@ -87,6 +131,20 @@ func (t *tqState) GetTestable() tq.Testable {
 	return t.tq.GetTestable()
 }
 
+func taskInfo(tasks []*tq.Task, queueName string) func(int) CallInfo {
+	return func(i int) CallInfo {
+		return CallInfo{QueueName: queueName, TaskName: tasks[i].Name}
+	}
+}
+
+func pickTasks(tasks []*tq.Task, idxs []int) []*tq.Task {
+	ret := make([]*tq.Task, len(idxs))
+	for j, i := range idxs {
+		ret[j] = tasks[i]
+	}
+	return ret
+}
+
 // FilterTQ installs a featureBreaker TaskQueue filter in the context.
 func FilterTQ(c context.Context, defaultError error) (context.Context, FeatureBreaker) {
 	state := newState(defaultError)
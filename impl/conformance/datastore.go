@@ -0,0 +1,481 @@
+// Copyright 2017 The LUCI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	ds "go.chromium.org/gae/service/datastore"
+	"go.chromium.org/gae/service/info"
+	"go.chromium.org/luci/common/errors"
+
+	"golang.org/x/net/context"
+
+	. "github.com/smartystreets/goconvey/convey"
+	. "go.chromium.org/luci/common/testing/assertions"
+)
+
+// conformanceEntity is the entity type used by RunDatastoreTests.
+type conformanceEntity struct {
+	_kind  string  `gae:"$kind,ConformanceEntity"`
+	ID     int64   `gae:"$id"`
+	Parent *ds.Key `gae:"$parent"`
+
+	Value int64
+	Tags  []string
+	Blob  []byte `gae:",noindex"`
+}
+
+func values(ents []*conformanceEntity) []int64 {
+	ret := make([]int64, len(ents))
+	for i, e := range ents {
+		ret[i] = e.Value
+	}
+	return ret
+}
+
+// RunDatastoreTests runs the datastore conformance suite against the
+// datastore implementation installed in the Contexts returned by factory.
+//
+// Queries issued by the suite must be strongly consistent, and must not
+// require composite indexes to be declared ahead of time, and Put must reject
+// entities which exceed the production datastore's size limits. For the memory
+// implementation, this means enabling Consistent and AutoIndex on its
+// Testable, and calling EnforceEntityLimits on its Datastore.
+func RunDatastoreTests(t *testing.T, factory func() context.Context) {
+	Convey("Datastore conformance", t, func() {
+		c := factory()
+
+		Convey("CRUD", func() {
+			e := &conformanceEntity{Value: 10, Tags: []string{"a", "b"}}
+			So(ds.Put(c, e), ShouldBeNil)
+			So(e.ID, ShouldNotEqual, 0)
+
+			Convey("Get returns what was Put", func() {
+				got := &conformanceEntity{ID: e.ID}
+				So(ds.Get(c, got), ShouldBeNil)
+				So(got, ShouldResemble, e)
+			})
+
+			Convey("Put overwrites", func() {
+				e.Value = 20
+				e.Tags = nil
+				So(ds.Put(c, e), ShouldBeNil)
+
+				got := &conformanceEntity{ID: e.ID}
+				So(ds.Get(c, got), ShouldBeNil)
+				So(got.Value, ShouldEqual, 20)
+				So(got.Tags, ShouldBeNil)
+			})
+
+			Convey("Delete removes", func() {
+				So(ds.Delete(c, e), ShouldBeNil)
+				So(ds.Get(c, &conformanceEntity{ID: e.ID}), ShouldEqual, ds.ErrNoSuchEntity)
+
+				ex, err := ds.Exists(c, e)
+				So(err, ShouldBeNil)
+				So(ex.All(), ShouldBeFalse)
+
+				Convey("and deleting again is not an error", func() {
+					So(ds.Delete(c, e), ShouldBeNil)
+				})
+			})
+
+			Convey("AllocateIDs assigns distinct IDs", func() {
+				ents := []*conformanceEntity{{}, {}, {}}
+				So(ds.AllocateIDs(c, ents), ShouldBeNil)
+				seen := map[int64]bool{}
+				for _, a := range ents {
+					So(a.ID, ShouldNotEqual, 0)
+					So(seen[a.ID], ShouldBeFalse)
+					seen[a.ID] = true
+				}
+			})
+
+			Convey("large noindex values round trip", func() {
+				big := &conformanceEntity{ID: 1, Blob: bytes.Repeat([]byte("x"), 100*1024)}
+				So(ds.Put(c, big), ShouldBeNil)
+
+				got := &conformanceEntity{ID: 1}
+				So(ds.Get(c, got), ShouldBeNil)
+				So(got.Blob, ShouldResemble, big.Blob)
+			})
+		})
+
+		Convey("meta", func() {
+			Convey("$kind, $id and $parent make up the key", func() {
+				parent := ds.MakeKey(c, "Parent", "p")
+				e := &conformanceEntity{ID: 5, Parent: parent}
+				So(ds.KeyForObj(c, e), ShouldResemble, ds.MakeKey(c, "Parent", "p", "ConformanceEntity", 5))
+			})
+
+			Convey("PropertyMap round trips with $key", func() {
+				k := ds.MakeKey(c, "ConformanceEntity", 7)
+				pm := ds.PropertyMap{
+					"$key":  ds.MkPropertyNI(k),
+					"Value": ds.MkProperty(7),
+				}
+				So(ds.Put(c, pm), ShouldBeNil)
+
+				got := &conformanceEntity{ID: 7}
+				So(ds.Get(c, got), ShouldBeNil)
+				So(got.Value, ShouldEqual, 7)
+
+				gotPM := ds.PropertyMap{"$key": ds.MkPropertyNI(k)}
+				So(ds.Get(c, gotPM), ShouldBeNil)
+				So(gotPM.Slice("Value"), ShouldResemble, ds.PropertySlice{ds.MkProperty(7)})
+			})
+		})
+
+		Convey("multi-op errors are aligned with their inputs", func() {
+			So(ds.Put(c, []*conformanceEntity{{ID: 1, Value: 1}, {ID: 3, Value: 3}}), ShouldBeNil)
+
+			Convey("Get", func() {
+				ents := []*conformanceEntity{{ID: 1}, {ID: 2}, {ID: 3}}
+				err := ds.Get(c, ents)
+				So(err, ShouldResemble, errors.MultiError{nil, ds.ErrNoSuchEntity, nil})
+				So(ents[0].Value, ShouldEqual, 1)
+				So(ents[2].Value, ShouldEqual, 3)
+			})
+
+			Convey("Exists", func() {
+				ex, err := ds.Exists(c, []*conformanceEntity{{ID: 1}, {ID: 2}, {ID: 3}})
+				So(err, ShouldBeNil)
+				So(ex.List(0), ShouldResemble, ds.BoolList{true, false, true})
+				So(ex.All(), ShouldBeFalse)
+			})
+
+			Convey("Put with an invalid key", func() {
+				other := ds.MkKeyContext("not-"+info.AppID(c), "").MakeKey("ConformanceEntity", 2)
+				err := ds.Put(c, []ds.PropertyMap{
+					{"$key": ds.MkPropertyNI(ds.MakeKey(c, "ConformanceEntity", 4))},
+					{"$key": ds.MkPropertyNI(other)},
+				})
+				me, ok := err.(errors.MultiError)
+				So(ok, ShouldBeTrue)
+				So(len(me), ShouldEqual, 2)
+				So(ds.IsErrInvalidKey(me[1]), ShouldBeTrue)
+			})
+
+			Convey("Get with an incomplete key", func() {
+				err := ds.Get(c, []ds.PropertyMap{
+					{"$key": ds.MkPropertyNI(ds.MakeKey(c, "ConformanceEntity", 1))},
+					{"$key": ds.MkPropertyNI(ds.NewIncompleteKeys(c, 1, "ConformanceEntity", nil)[0])},
+				})
+				me, ok := err.(errors.MultiError)
+				So(ok, ShouldBeTrue)
+				So(len(me), ShouldEqual, 2)
+				So(ds.IsErrInvalidKey(me[1]), ShouldBeTrue)
+			})
+
+			Convey("Delete", func() {
+				So(ds.Delete(c, []*ds.Key{
+					ds.MakeKey(c, "ConformanceEntity", 1),
+					ds.MakeKey(c, "ConformanceEntity", 2),
+				}), ShouldBeNil)
+
+				ex, err := ds.Exists(c, []*conformanceEntity{{ID: 1}, {ID: 3}})
+				So(err, ShouldBeNil)
+				So(ex.List(0), ShouldResemble, ds.BoolList{false, true})
+			})
+		})
+
+		Convey("size limits", func() {
+			ok := &conformanceEntity{ID: 1, Value: 1}
+			key := func(id int64) *ds.Key { return ds.MakeKey(c, "ConformanceEntity", id) }
+
+			// rejected asserts that err rejected the whole Put, rather than being
+			// aligned with the individual entities, and that nothing was written.
+			rejected := func(err error) {
+				So(err, ShouldNotBeNil)
+				_, isMulti := err.(errors.MultiError)
+				So(isMulti, ShouldBeFalse)
+
+				ex, err := ds.Exists(c, []*ds.Key{key(1), key(2)})
+				So(err, ShouldBeNil)
+				So(ex.List(0), ShouldResemble, ds.BoolList{false, false})
+			}
+
+			Convey("an oversized entity rejects the whole Put", func() {
+				big := &conformanceEntity{ID: 2, Blob: bytes.Repeat([]byte("x"), 1024*1024+1)}
+				rejected(ds.Put(c, ok, big))
+			})
+
+			Convey("an indexed string over 1500 bytes rejects the whole Put", func() {
+				long := &conformanceEntity{ID: 2, Tags: []string{strings.Repeat("x", 1501)}}
+				rejected(ds.Put(c, ok, long))
+
+				Convey("but is fine unindexed", func() {
+					So(ds.Put(c, ds.PropertyMap{
+						"$key": ds.MkPropertyNI(key(2)),
+						"Long": ds.MkPropertyNI(strings.Repeat("x", 1501)),
+					}), ShouldBeNil)
+				})
+			})
+
+			Convey("an indexed []byte over 1500 bytes rejects the whole Put", func() {
+				long := ds.PropertyMap{
+					"$key":  ds.MkPropertyNI(key(2)),
+					"Bytes": ds.MkProperty(bytes.Repeat([]byte("x"), 1501)),
+				}
+				rejected(ds.Put(c, ok, long))
+			})
+		})
+
+		Convey("batch size limits", func() {
+			cons := ds.Raw(c).Constraints()
+
+			Convey("Put", func() {
+				So(cons.MaxPutSize, ShouldBeGreaterThan, 0)
+				ents := make([]*conformanceEntity, cons.MaxPutSize+1)
+				for i := range ents {
+					ents[i] = &conformanceEntity{ID: int64(i + 1), Value: int64(i)}
+				}
+
+				Convey("over MaxPutSize is rejected without batching", func() {
+					So(ds.Put(ds.WithBatching(c, false), ents), ShouldErrLike, "exceeds maximum")
+
+					ex, err := ds.Exists(c, ents[0])
+					So(err, ShouldBeNil)
+					So(ex.All(), ShouldBeFalse)
+				})
+
+				Convey("over MaxPutSize is split into aligned batches", func() {
+					for _, e := range ents {
+						e.ID = 0
+					}
+					So(ds.Put(c, ents), ShouldBeNil)
+
+					got := make([]*conformanceEntity, len(ents))
+					for i, e := range ents {
+						So(e.ID, ShouldNotEqual, 0)
+						got[i] = &conformanceEntity{ID: e.ID}
+					}
+					So(ds.Get(c, got), ShouldBeNil)
+					So(values(got), ShouldResemble, values(ents))
+				})
+			})
+
+			Convey("Get", func() {
+				So(cons.MaxGetSize, ShouldBeGreaterThan, 0)
+				ents := make([]*conformanceEntity, cons.MaxGetSize+1)
+				for i := range ents {
+					ents[i] = &conformanceEntity{ID: int64(i + 1)}
+				}
+				last := len(ents) - 1
+				So(ds.Put(c, &conformanceEntity{ID: ents[last].ID, Value: 100}), ShouldBeNil)
+
+				Convey("over MaxGetSize is rejected without batching", func() {
+					So(ds.Get(ds.WithBatching(c, false), ents), ShouldErrLike, "exceeds maximum")
+				})
+
+				Convey("over MaxGetSize is split into aligned batches", func() {
+					err := ds.Get(c, ents)
+					me, ok := err.(errors.MultiError)
+					So(ok, ShouldBeTrue)
+					So(len(me), ShouldEqual, len(ents))
+					for i, err := range me[:last] {
+						if err != ds.ErrNoSuchEntity {
+							So(fmt.Sprintf("entity %d: %v", i, err), ShouldBeEmpty)
+						}
+					}
+					So(me[last], ShouldBeNil)
+					So(ents[last].Value, ShouldEqual, 100)
+				})
+			})
+		})
+
+		Convey("transactions", func() {
+			Convey("commit", func() {
+				So(ds.CurrentTransaction(c), ShouldBeNil)
+				So(ds.RunInTransaction(c, func(c context.Context) error {
+					So(ds.CurrentTransaction(c), ShouldNotBeNil)
+					return ds.Put(c, &conformanceEntity{ID: 1, Value: 1})
+				}, nil), ShouldBeNil)
+
+				got := &conformanceEntity{ID: 1}
+				So(ds.Get(c, got), ShouldBeNil)
+				So(got.Value, ShouldEqual, 1)
+			})
+
+			Convey("roll back on error", func() {
+				boom := errors.New("boom")
+				So(ds.RunInTransaction(c, func(c context.Context) error {
+					So(ds.Put(c, &conformanceEntity{ID: 1, Value: 1}), ShouldBeNil)
+					return boom
+				}, nil), ShouldEqual, boom)
+
+				So(ds.Get(c, &conformanceEntity{ID: 1}), ShouldEqual, ds.ErrNoSuchEntity)
+			})
+
+			Convey("read committed data", func() {
+				So(ds.Put(c, &conformanceEntity{ID: 1, Value: 1}), ShouldBeNil)
+				So(ds.RunInTransaction(c, func(c context.Context) error {
+					got := &conformanceEntity{ID: 1}
+					So(ds.Get(c, got), ShouldBeNil)
+					So(got.Value, ShouldEqual, 1)
+
+					got.Value++
+					return ds.Put(c, got)
+				}, nil), ShouldBeNil)
+
+				got := &conformanceEntity{ID: 1}
+				So(ds.Get(c, got), ShouldBeNil)
+				So(got.Value, ShouldEqual, 2)
+			})
+		})
+
+		Convey("queries", func() {
+			ents := make([]*conformanceEntity, 5)
+			for i := range ents {
+				ents[i] = &conformanceEntity{ID: int64(i + 1), Value: int64(i + 1)}
+				if i%2 == 1 {
+					ents[i].Tags = []string{"even"}
+				}
+			}
+			So(ds.Put(c, ents), ShouldBeNil)
+			q := ds.NewQuery("ConformanceEntity")
+
+			Convey("inequality with order", func() {
+				var got []*conformanceEntity
+				So(ds.GetAll(c, q.Gt("Value", 2).Order("-Value"), &got), ShouldBeNil)
+				So(values(got), ShouldResemble, []int64{5, 4, 3})
+			})
+
+			Convey("equality on a multi-valued property", func() {
+				var got []*conformanceEntity
+				So(ds.GetAll(c, q.Eq("Tags", "even"), &got), ShouldBeNil)
+				So(values(got), ShouldResemble, []int64{2, 4})
+			})
+
+			Convey("limit and offset", func() {
+				var got []*conformanceEntity
+				So(ds.GetAll(c, q.Order("Value").Offset(1).Limit(2), &got), ShouldBeNil)
+				So(values(got), ShouldResemble, []int64{2, 3})
+			})
+
+			Convey("keys only", func() {
+				var keys []*ds.Key
+				So(ds.GetAll(c, q.Lte("Value", 2), &keys), ShouldBeNil)
+				So(keys, ShouldResemble, []*ds.Key{
+					ds.MakeKey(c, "ConformanceEntity", 1),
+					ds.MakeKey(c, "ConformanceEntity", 2),
+				})
+			})
+
+			Convey("count", func() {
+				n, err := ds.Count(c, q.Gte("Value", 2))
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 4)
+			})
+
+			Convey("projection", func() {
+				var got []ds.PropertyMap
+				So(ds.GetAll(c, q.Project("Value").Lt("Value", 3), &got), ShouldBeNil)
+				So(len(got), ShouldEqual, 2)
+				for i, pm := range got {
+					So(pm.Slice("Value"), ShouldResemble, ds.PropertySlice{ds.MkProperty(int64(i + 1))})
+					So(pm.Slice("Tags"), ShouldBeNil)
+				}
+			})
+
+			Convey("ancestor", func() {
+				parent := ds.MakeKey(c, "Parent", "p")
+				kids := []*conformanceEntity{
+					{ID: 2, Parent: parent, Value: 20},
+					{ID: 1, Parent: parent, Value: 10},
+				}
+				So(ds.Put(c, kids), ShouldBeNil)
+
+				var got []*conformanceEntity
+				So(ds.GetAll(c, q.Ancestor(parent), &got), ShouldBeNil)
+				So(values(got), ShouldResemble, []int64{10, 20})
+			})
+
+			Convey("cursors resume where they left off", func() {
+				var cursor ds.Cursor
+				var first []int64
+				So(ds.Run(c, q.Order("Value").Limit(2), func(e *conformanceEntity, getCursor ds.CursorCB) error {
+					first = append(first, e.Value)
+					if len(first) == 2 {
+						var err error
+						cursor, err = getCursor()
+						return err
+					}
+					return nil
+				}), ShouldBeNil)
+				So(first, ShouldResemble, []int64{1, 2})
+				So(cursor, ShouldNotBeNil)
+
+				Convey("directly", func() {
+					var rest []*conformanceEntity
+					So(ds.GetAll(c, q.Order("Value").Start(cursor), &rest), ShouldBeNil)
+					So(values(rest), ShouldResemble, []int64{3, 4, 5})
+				})
+
+				Convey("after a round trip through a string", func() {
+					decoded, err := ds.DecodeCursor(c, cursor.String())
+					So(err, ShouldBeNil)
+
+					var rest []*conformanceEntity
+					So(ds.GetAll(c, q.Order("Value").Start(decoded), &rest), ShouldBeNil)
+					So(values(rest), ShouldResemble, []int64{3, 4, 5})
+				})
+			})
+
+			Convey("validation", func() {
+				var got []*conformanceEntity
+
+				Convey("inequalities on two properties", func() {
+					So(ds.GetAll(c, q.Gt("Value", 1).Lt("Other", 2), &got), ShouldErrLike,
+						"inequality filters on multiple properties")
+				})
+
+				Convey("first sort order must match the inequality", func() {
+					So(ds.GetAll(c, q.Gt("Value", 1).Order("Tags"), &got), ShouldErrLike,
+						"first sort order must match inequality filter")
+				})
+
+				Convey("kindless queries may not filter on properties", func() {
+					So(ds.GetAll(c, ds.NewQuery("").Eq("Value", 1), &got), ShouldErrLike,
+						"kindless queries may not have any equality filters")
+				})
+			})
+		})
+
+		Convey("namespaces are isolated", func() {
+			nsA := info.MustNamespace(c, info.GetNamespace(c)+"conformanceA")
+			nsB := info.MustNamespace(c, info.GetNamespace(c)+"conformanceB")
+
+			e := &conformanceEntity{ID: 1, Value: 1}
+			So(ds.Put(nsA, e), ShouldBeNil)
+			So(ds.KeyForObj(nsA, e).Namespace(), ShouldEqual, info.GetNamespace(nsA))
+
+			So(ds.Get(nsB, &conformanceEntity{ID: 1}), ShouldEqual, ds.ErrNoSuchEntity)
+			So(ds.Get(c, &conformanceEntity{ID: 1}), ShouldEqual, ds.ErrNoSuchEntity)
+
+			n, err := ds.Count(nsB, ds.NewQuery("ConformanceEntity"))
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 0)
+
+			n, err = ds.Count(nsA, ds.NewQuery("ConformanceEntity"))
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,36 @@
+// Copyright 2017 The LUCI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package conformance contains test suites which verify that an
+// implementation of the gae services behaves the way that this library
+// expects.
+//
+// Each suite takes a factory function which returns a Context with the
+// implementation under test installed. The factory is called once for every
+// test case, and each returned Context must observe fresh, empty service state
+// (for example, by using a new in-memory instance, or a unique namespace).
+//
+// To run a suite against an implementation, call it from a regular Go test:
+//
+//   func TestConformance(t *testing.T) {
+//     t.Parallel()
+//
+//     conformance.RunDatastoreTests(t, func() context.Context {
+//       return myimpl.Use(context.Background())
+//     })
+//   }
+//
+// The in-memory implementation (impl/memory) is validated against these
+// suites, so they describe the behavior of a reference implementation.
+package conformance
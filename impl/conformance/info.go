@@ -0,0 +1,73 @@
+// Copyright 2017 The LUCI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"strings"
+	"testing"
+
+	"go.chromium.org/gae/service/info"
+
+	"golang.org/x/net/context"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// RunInfoTests runs the info conformance suite against the info
+// implementation installed in the Contexts returned by factory.
+func RunInfoTests(t *testing.T, factory func() context.Context) {
+	Convey("Info conformance", t, func() {
+		c := factory()
+
+		Convey("identity", func() {
+			aid := info.AppID(c)
+			So(aid, ShouldNotEqual, "")
+			So(info.FullyQualifiedAppID(c), ShouldEndWith, aid)
+			So(info.VersionID(c), ShouldNotEqual, "")
+			So(info.ModuleName(c), ShouldNotEqual, "")
+			So(strings.Count(info.TrimmedAppID(c), ":"), ShouldEqual, 0)
+		})
+
+		Convey("Namespace derives a new Context", func() {
+			base := info.GetNamespace(c)
+			ns := base + "conformance"
+
+			nc, err := info.Namespace(c, ns)
+			So(err, ShouldBeNil)
+			So(info.GetNamespace(nc), ShouldEqual, ns)
+			So(info.GetNamespace(c), ShouldEqual, base)
+
+			Convey("which can be switched back", func() {
+				So(info.GetNamespace(info.MustNamespace(nc, base)), ShouldEqual, base)
+			})
+
+			Convey("and keeps the identity", func() {
+				So(info.AppID(nc), ShouldEqual, info.AppID(c))
+				So(info.FullyQualifiedAppID(nc), ShouldEqual, info.FullyQualifiedAppID(c))
+			})
+		})
+
+		Convey("invalid namespaces are rejected", func() {
+			_, err := info.Namespace(c, "not a valid namespace!")
+			So(err, ShouldNotBeNil)
+			So(func() { info.MustNamespace(c, "not a valid namespace!") }, ShouldPanic)
+		})
+
+		Convey("error classifiers don't match unrelated errors", func() {
+			So(info.IsOverQuota(c, context.Canceled), ShouldBeFalse)
+			So(info.IsTimeoutError(c, context.Canceled), ShouldBeFalse)
+		})
+	})
+}
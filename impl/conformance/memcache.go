@@ -0,0 +1,133 @@
+// Copyright 2017 The LUCI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"testing"
+
+	"go.chromium.org/gae/service/info"
+	mc "go.chromium.org/gae/service/memcache"
+	"go.chromium.org/luci/common/errors"
+
+	"golang.org/x/net/context"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// RunMemcacheTests runs the memcache conformance suite against the memcache
+// implementation installed in the Contexts returned by factory.
+func RunMemcacheTests(t *testing.T, factory func() context.Context) {
+	Convey("Memcache conformance", t, func() {
+		c := factory()
+
+		So(mc.Set(c, mc.NewItem(c, "a").SetValue([]byte("A")).SetFlags(7)), ShouldBeNil)
+
+		Convey("Get returns what was Set", func() {
+			itm, err := mc.GetKey(c, "a")
+			So(err, ShouldBeNil)
+			So(itm.Key(), ShouldEqual, "a")
+			So(itm.Value(), ShouldResemble, []byte("A"))
+			So(itm.Flags(), ShouldEqual, 7)
+		})
+
+		Convey("Get of a missing key is a cache miss", func() {
+			_, err := mc.GetKey(c, "missing")
+			So(err, ShouldEqual, mc.ErrCacheMiss)
+		})
+
+		Convey("multi-op errors are aligned with their inputs", func() {
+			So(mc.Set(c, mc.NewItem(c, "b").SetValue([]byte("B"))), ShouldBeNil)
+
+			Convey("Get", func() {
+				itms := []mc.Item{mc.NewItem(c, "a"), mc.NewItem(c, "missing"), mc.NewItem(c, "b")}
+				So(mc.Get(c, itms...), ShouldResemble, errors.MultiError{nil, mc.ErrCacheMiss, nil})
+				So(itms[0].Value(), ShouldResemble, []byte("A"))
+				So(itms[2].Value(), ShouldResemble, []byte("B"))
+			})
+
+			Convey("Add", func() {
+				err := mc.Add(c,
+					mc.NewItem(c, "a").SetValue([]byte("nope")),
+					mc.NewItem(c, "c").SetValue([]byte("C")))
+				So(err, ShouldResemble, errors.MultiError{mc.ErrNotStored, nil})
+
+				itm, err := mc.GetKey(c, "a")
+				So(err, ShouldBeNil)
+				So(itm.Value(), ShouldResemble, []byte("A"))
+			})
+
+			Convey("Delete", func() {
+				So(mc.Delete(c, "a", "missing", "b"), ShouldResemble,
+					errors.MultiError{nil, mc.ErrCacheMiss, nil})
+				So(mc.Get(c, mc.NewItem(c, "a"), mc.NewItem(c, "b")), ShouldResemble,
+					errors.MultiError{mc.ErrCacheMiss, mc.ErrCacheMiss})
+			})
+		})
+
+		Convey("CompareAndSwap", func() {
+			itm, err := mc.GetKey(c, "a")
+			So(err, ShouldBeNil)
+
+			itm.SetValue([]byte("new"))
+			So(mc.CompareAndSwap(c, itm), ShouldBeNil)
+
+			got, err := mc.GetKey(c, "a")
+			So(err, ShouldBeNil)
+			So(got.Value(), ShouldResemble, []byte("new"))
+
+			Convey("fails with a stale item", func() {
+				itm.SetValue([]byte("stale"))
+				So(mc.CompareAndSwap(c, itm), ShouldEqual, mc.ErrCASConflict)
+			})
+
+			Convey("fails once the item is gone", func() {
+				So(mc.Delete(c, "a"), ShouldBeNil)
+				So(mc.CompareAndSwap(c, got), ShouldEqual, mc.ErrNotStored)
+			})
+		})
+
+		Convey("Increment", func() {
+			v, err := mc.Increment(c, "counter", 5, 10)
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, 15)
+
+			v, err = mc.IncrementExisting(c, "counter", -100)
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, 0)
+
+			_, err = mc.IncrementExisting(c, "missing", 1)
+			So(err, ShouldEqual, mc.ErrCacheMiss)
+		})
+
+		Convey("Flush removes everything", func() {
+			So(mc.Flush(c), ShouldBeNil)
+			_, err := mc.GetKey(c, "a")
+			So(err, ShouldEqual, mc.ErrCacheMiss)
+		})
+
+		Convey("namespaces are isolated", func() {
+			nsA := info.MustNamespace(c, info.GetNamespace(c)+"conformanceA")
+			So(mc.Set(nsA, mc.NewItem(nsA, "a").SetValue([]byte("nsA"))), ShouldBeNil)
+
+			itm, err := mc.GetKey(c, "a")
+			So(err, ShouldBeNil)
+			So(itm.Value(), ShouldResemble, []byte("A"))
+
+			itm, err = mc.GetKey(nsA, "a")
+			So(err, ShouldBeNil)
+			So(itm.Value(), ShouldResemble, []byte("nsA"))
+		})
+	})
+}
@@ -0,0 +1,98 @@
+// Copyright 2017 The LUCI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"testing"
+
+	tq "go.chromium.org/gae/service/taskqueue"
+	"go.chromium.org/luci/common/errors"
+
+	"golang.org/x/net/context"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// RunTaskQueueTests runs the taskqueue conformance suite against the
+// taskqueue implementation installed in the Contexts returned by factory.
+//
+// The suite only uses the "default" push queue, which must exist.
+func RunTaskQueueTests(t *testing.T, factory func() context.Context) {
+	Convey("TaskQueue conformance", t, func() {
+		c := factory()
+
+		stats := func() tq.Statistics {
+			s, err := tq.Stats(c, "default")
+			So(err, ShouldBeNil)
+			So(len(s), ShouldEqual, 1)
+			return s[0]
+		}
+
+		Convey("Add assigns names to unnamed tasks", func() {
+			task := &tq.Task{Path: "/work"}
+			So(tq.Add(c, "default", task), ShouldBeNil)
+			So(task.Name, ShouldNotEqual, "")
+			So(stats().Tasks, ShouldEqual, 1)
+		})
+
+		Convey("named tasks", func() {
+			So(tq.Add(c, "default", &tq.Task{Name: "named", Path: "/work"}), ShouldBeNil)
+
+			Convey("can't be added twice", func() {
+				So(tq.Add(c, "default", &tq.Task{Name: "named", Path: "/work"}), ShouldEqual, tq.ErrTaskAlreadyAdded)
+			})
+
+			Convey("can't be re-added after deletion", func() {
+				So(tq.Delete(c, "default", &tq.Task{Name: "named"}), ShouldBeNil)
+				So(stats().Tasks, ShouldEqual, 0)
+				So(tq.Add(c, "default", &tq.Task{Name: "named", Path: "/work"}), ShouldEqual, tq.ErrTaskAlreadyAdded)
+			})
+		})
+
+		Convey("multi-op errors are aligned with their inputs", func() {
+			So(tq.Add(c, "default", &tq.Task{Name: "a", Path: "/work"}), ShouldBeNil)
+
+			Convey("Add", func() {
+				err := tq.Add(c, "default",
+					&tq.Task{Name: "b", Path: "/work"},
+					&tq.Task{Name: "a", Path: "/work"},
+					&tq.Task{Name: "c", Path: "/work"})
+				So(err, ShouldResemble, errors.MultiError{nil, tq.ErrTaskAlreadyAdded, nil})
+				So(stats().Tasks, ShouldEqual, 3)
+			})
+
+			Convey("Delete", func() {
+				err := tq.Delete(c, "default", &tq.Task{Name: "missing"}, &tq.Task{Name: "a"})
+				me, ok := err.(errors.MultiError)
+				So(ok, ShouldBeTrue)
+				So(len(me), ShouldEqual, 2)
+				So(me[0], ShouldNotBeNil)
+				So(me[1], ShouldBeNil)
+			})
+		})
+
+		Convey("unknown queues are rejected", func() {
+			So(tq.Add(c, "conformance-no-such-queue", &tq.Task{Path: "/work"}), ShouldNotBeNil)
+		})
+
+		Convey("Purge removes all tasks", func() {
+			So(tq.Add(c, "default", &tq.Task{Path: "/work"}, &tq.Task{Path: "/work"}), ShouldBeNil)
+			So(stats().Tasks, ShouldEqual, 2)
+
+			So(tq.Purge(c, "default"), ShouldBeNil)
+			So(stats().Tasks, ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,42 @@
+// Copyright 2017 The LUCI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"testing"
+
+	"go.chromium.org/gae/impl/conformance"
+	ds "go.chromium.org/gae/service/datastore"
+
+	"golang.org/x/net/context"
+)
+
+func TestConformance(t *testing.T) {
+	t.Parallel()
+
+	conformance.RunDatastoreTests(t, func() context.Context {
+		c := Use(context.Background())
+		tds := ds.GetTestable(c)
+		tds.Consistent(true)
+		tds.AutoIndex(true)
+		tds.(*Datastore).EnforceEntityLimits(true)
+		return c
+	})
+
+	use := func() context.Context { return Use(context.Background()) }
+	conformance.RunMemcacheTests(t, use)
+	conformance.RunTaskQueueTests(t, use)
+	conformance.RunInfoTests(t, use)
+}
@@ -82,11 +82,8 @@ type memContext []memContextObj
 
 var _ memContextObj = (memContext)(nil)
 
-func newMemContext(aid string) memContext {
-	return memContext{
-		newTaskQueueData(),
-		newDataStoreData(aid),
-	}
+func newMemContext(d *Datastore, t *TaskQueue) memContext {
+	return memContext{t, d}
 }
 
 type memContextIdx int
@@ -139,6 +136,12 @@ func Use(c context.Context) context.Context {
 // context. If 'aid' contains a "~" character, it will be treated as the
 // fully-qualified App ID and the AppID will be the string following the "~".
 func UseInfo(c context.Context, aid string) context.Context {
+	return useMemContext(c, aid, newDatastore(aid), newTaskQueue())
+}
+
+// useMemContext installs d and t as the Context's datastore and taskqueue
+// state, along with an info implementation for 'aid' (as in UseInfo).
+func useMemContext(c context.Context, aid string, d *Datastore, t *TaskQueue) context.Context {
 	if c.Value(&memContextKey) != nil {
 		panic(errors.New("memory.Use: called twice on the same Context"))
 	}
@@ -148,8 +151,7 @@ func UseInfo(c context.Context, aid string) context.Context {
 		aid = parts[1]
 	}
 
-	memctx := newMemContext(fqAppID)
-	c = context.WithValue(c, &memContextKey, memctx)
+	c = context.WithValue(c, &memContextKey, newMemContext(d, t))
 
 	return useGI(useGID(c, func(mod *globalInfoData) {
 		mod.appID = aid
@@ -203,21 +205,21 @@ var (
 // a couple ways, for example.
 //
 // It really should have been appengine.Context.RunInTransaction(func(tc...)),
-// but because it's not, this method is on dsImpl instead to mirror the official
+// but because it's not, this method is on Datastore instead to mirror the official
 // API.
 //
 // The fake implementation also differs from the real implementation because the
 // fake TaskQueue is NOT backed by the fake Datastore. This is done to make the
 // test-access API for TaskQueue better (instead of trying to reconstitute the
 // state of the task queue from a bunch of datastore accesses).
-func (d *dsImpl) RunInTransaction(f func(context.Context) error, o *ds.TransactionOptions) error {
+func (d *Datastore) RunInTransaction(f func(context.Context) error, o *ds.TransactionOptions) error {
 	if d.data.getDisableSpecialEntities() {
 		return errors.New("special entities are disabled. no transactions for you")
 	}
 
 	// Keep in separate function for defers.
 	loopBody := func(applyForReal bool) error {
-		curMC, inTxn := cur(d)
+		curMC, inTxn := cur(d.ctx)
 		if inTxn {
 			return errors.New("datastore: nested transactions are not supported")
 		}
@@ -225,7 +227,7 @@ func (d *dsImpl) RunInTransaction(f func(context.Context) error, o *ds.Transacti
 		txnMC := curMC.mkTxn(o)
 		defer txnMC.endTxn()
 
-		if err := f(context.WithValue(d, &currentTxnKey, txnMC)); err != nil {
+		if err := f(context.WithValue(d.ctx, &currentTxnKey, txnMC)); err != nil {
 			return err
 		}
 
@@ -233,7 +235,7 @@ func (d *dsImpl) RunInTransaction(f func(context.Context) error, o *ds.Transacti
 			return ds.ErrConcurrentTransaction
 		}
 
-		commitOp := curMC.beginCommit(d, txnMC)
+		commitOp := curMC.beginCommit(d.ctx, txnMC)
 		if commitOp == nil {
 			return ds.ErrConcurrentTransaction
 		}
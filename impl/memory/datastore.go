@@ -36,7 +36,7 @@ func useRDS(c context.Context) context.Context {
 		if isTxn {
 			return &txnDsImpl{ic, dsd.(*txnDataStoreData), kc}
 		}
-		return &dsImpl{ic, dsd.(*dataStoreData), kc}
+		return dsd.(*Datastore).bind(ic, kc)
 	})
 }
 
@@ -52,10 +52,8 @@ func useRDS(c context.Context) context.Context {
 func NewDatastore(c context.Context, inf info.RawInterface) ds.RawInterface {
 	kc := ds.GetKeyContext(c)
 
-	memctx := newMemContext(kc.AppID)
-
 	dsCtx := info.Set(context.Background(), inf)
-	rds := &dsImpl{dsCtx, memctx.Get(memContextDSIdx).(*dataStoreData), kc}
+	rds := newDatastore(kc.AppID).bind(dsCtx, kc)
 
 	ret := ds.Raw(ds.SetRaw(dsCtx, rds))
 	t := ret.GetTestable()
@@ -66,41 +64,94 @@ func NewDatastore(c context.Context, inf info.RawInterface) ds.RawInterface {
 	return ret
 }
 
-//////////////////////////////////// dsImpl ////////////////////////////////////
+/////////////////////////////////// Datastore //////////////////////////////////
 
-// dsImpl exists solely to bind the current c to the datastore data.
-type dsImpl struct {
-	context.Context
+// Datastore is an in-memory datastore. It implements datastore.RawInterface
+// and datastore.Testable directly, so it can be used without installing it in
+// a context.Context.
+//
+// UseWithAppID installs a Datastore in the context, and the datastore service
+// functions operate on copies of it which are bound to their Context. All
+// copies share the same underlying data.
+type Datastore struct {
+	// ctx is the Context that this Datastore is bound to. It's used to run
+	// transactions, and is returned by WithoutTransaction.
+	ctx context.Context
 
 	data *dataStoreData
 	kc   ds.KeyContext
 }
 
-var _ ds.RawInterface = (*dsImpl)(nil)
+var _ ds.RawInterface = (*Datastore)(nil)
+var _ ds.Testable = (*Datastore)(nil)
+var _ ds.QueryExplainer = (*Datastore)(nil)
+var _ memContextObj = (*Datastore)(nil)
+
+// newDatastore returns a new, empty Datastore for the fully-qualified App ID
+// 'fqAppID'. It isn't bound to any Context.
+func newDatastore(fqAppID string) *Datastore {
+	return &Datastore{
+		data: newDataStoreData(fqAppID),
+		kc:   ds.MkKeyContext(fqAppID, ""),
+	}
+}
+
+// NewStandaloneDatastore returns a new, empty Datastore for the application
+// 'appID'. It starts with the same (eventually consistent) state as the
+// Datastore installed by UseWithAppID.
+//
+// If 'appID' contains a "~" character, it will be treated as the
+// fully-qualified App ID, as with UseWithAppID.
+//
+// The Context passed to RunInTransaction callbacks is a private Context with
+// this Datastore installed, so the datastore package functions may be used
+// inside of the transaction.
+func NewStandaloneDatastore(appID string) *Datastore {
+	d := newDatastore(appID)
+	d.ctx = useRDS(useMemContext(context.Background(), appID, d, newTaskQueue()))
+	return d
+}
+
+// bind returns a copy of d which shares its data, but is bound to the Context
+// c and the KeyContext kc.
+func (d *Datastore) bind(c context.Context, kc ds.KeyContext) *Datastore {
+	return &Datastore{c, d.data, kc}
+}
+
+func (d *Datastore) beginCommit(c context.Context, txnCtxObj memContextObj) txnCommitOp {
+	return d.data.beginCommit(c, txnCtxObj)
+}
+
+func (d *Datastore) mkTxn(o *ds.TransactionOptions) memContextObj { return d.data.mkTxn(o) }
 
-func (d *dsImpl) AllocateIDs(keys []*ds.Key, cb ds.NewKeyCB) error {
+func (d *Datastore) endTxn() { d.data.endTxn() }
+
+func (d *Datastore) AllocateIDs(keys []*ds.Key, cb ds.NewKeyCB) error {
 	return d.data.allocateIDs(keys, cb)
 }
 
-func (d *dsImpl) PutMulti(keys []*ds.Key, vals []ds.PropertyMap, cb ds.NewKeyCB) error {
+func (d *Datastore) PutMulti(keys []*ds.Key, vals []ds.PropertyMap, cb ds.NewKeyCB) error {
+	if err := d.data.checkEntityLimits(vals); err != nil {
+		return err
+	}
 	d.data.putMulti(keys, vals, cb, false)
 	return nil
 }
 
-func (d *dsImpl) GetMulti(keys []*ds.Key, _meta ds.MultiMetaGetter, cb ds.GetMultiCB) error {
+func (d *Datastore) GetMulti(keys []*ds.Key, _meta ds.MultiMetaGetter, cb ds.GetMultiCB) error {
 	return d.data.getMulti(keys, cb)
 }
 
-func (d *dsImpl) DeleteMulti(keys []*ds.Key, cb ds.DeleteMultiCB) error {
+func (d *Datastore) DeleteMulti(keys []*ds.Key, cb ds.DeleteMultiCB) error {
 	d.data.delMulti(keys, cb, false)
 	return nil
 }
 
-func (d *dsImpl) DecodeCursor(s string) (ds.Cursor, error) {
+func (d *Datastore) DecodeCursor(s string) (ds.Cursor, error) {
 	return newCursor(s)
 }
 
-func (d *dsImpl) Run(fq *ds.FinalizedQuery, cb ds.RawRunCB) error {
+func (d *Datastore) Run(fq *ds.FinalizedQuery, cb ds.RawRunCB) error {
 	cb = d.data.stripSpecialPropsRunCB(cb)
 	idx, head := d.data.getQuerySnaps(!fq.EventuallyConsistent())
 	err := executeQuery(fq, d.kc, false, idx, head, cb)
@@ -111,7 +162,7 @@ func (d *dsImpl) Run(fq *ds.FinalizedQuery, cb ds.RawRunCB) error {
 	return err
 }
 
-func (d *dsImpl) Count(fq *ds.FinalizedQuery) (ret int64, err error) {
+func (d *Datastore) Count(fq *ds.FinalizedQuery) (ret int64, err error) {
 	idx, head := d.data.getQuerySnaps(!fq.EventuallyConsistent())
 	ret, err = countQuery(fq, d.kc, false, idx, head)
 	if d.data.maybeAutoIndex(err) {
@@ -121,14 +172,14 @@ func (d *dsImpl) Count(fq *ds.FinalizedQuery) (ret int64, err error) {
 	return
 }
 
-func (d *dsImpl) WithoutTransaction() context.Context {
+func (d *Datastore) WithoutTransaction() context.Context {
 	// Already not in a Transaction.
-	return d
+	return d.ctx
 }
 
-func (*dsImpl) CurrentTransaction() ds.Transaction { return nil }
+func (*Datastore) CurrentTransaction() ds.Transaction { return nil }
 
-func (d *dsImpl) AddIndexes(idxs ...*ds.IndexDefinition) {
+func (d *Datastore) AddIndexes(idxs ...*ds.IndexDefinition) {
 	if len(idxs) == 0 {
 		return
 	}
@@ -142,41 +193,50 @@ func (d *dsImpl) AddIndexes(idxs ...*ds.IndexDefinition) {
 	d.data.addIndexes(idxs)
 }
 
-func (d *dsImpl) Constraints() ds.Constraints { return d.data.getConstraints() }
+func (d *Datastore) Constraints() ds.Constraints { return d.data.getConstraints() }
 
-func (d *dsImpl) TakeIndexSnapshot() ds.TestingSnapshot {
+func (d *Datastore) TakeIndexSnapshot() ds.TestingSnapshot {
 	return d.data.takeSnapshot()
 }
 
-func (d *dsImpl) SetIndexSnapshot(snap ds.TestingSnapshot) {
+func (d *Datastore) SetIndexSnapshot(snap ds.TestingSnapshot) {
 	d.data.setSnapshot(snap.(memStore))
 }
 
-func (d *dsImpl) CatchupIndexes() {
+func (d *Datastore) CatchupIndexes() {
 	d.data.catchupIndexes()
 }
 
-func (d *dsImpl) SetTransactionRetryCount(count int) {
+func (d *Datastore) SetTransactionRetryCount(count int) {
 	d.data.setTxnRetry(count)
 }
 
-func (d *dsImpl) Consistent(always bool) {
+func (d *Datastore) Consistent(always bool) {
 	d.data.setConsistent(always)
 }
 
-func (d *dsImpl) AutoIndex(enable bool) {
+func (d *Datastore) AutoIndex(enable bool) {
 	d.data.setAutoIndex(enable)
 }
 
-func (d *dsImpl) DisableSpecialEntities(disabled bool) {
+func (d *Datastore) DisableSpecialEntities(disabled bool) {
 	d.data.setDisableSpecialEntities(disabled)
 }
 
-func (d *dsImpl) ShowSpecialProperties(show bool) {
+// EnforceEntityLimits controls whether Put rejects entities which the
+// production datastore would reject for their size: entities larger than
+// about 1MB, and indexed string or []byte values longer than 1500 bytes.
+//
+// By default this is false, so tests can store arbitrarily large entities.
+func (d *Datastore) EnforceEntityLimits(enable bool) {
+	d.data.setEnforceEntityLimits(enable)
+}
+
+func (d *Datastore) ShowSpecialProperties(show bool) {
 	d.data.setShowSpecialProperties(show)
 }
 
-func (d *dsImpl) SetConstraints(c *ds.Constraints) error {
+func (d *Datastore) SetConstraints(c *ds.Constraints) error {
 	if c == nil {
 		c = &ds.Constraints{}
 	}
@@ -184,7 +244,24 @@ func (d *dsImpl) SetConstraints(c *ds.Constraints) error {
 	return nil
 }
 
-func (d *dsImpl) GetTestable() ds.Testable { return d }
+func (d *Datastore) ExplainQuery(q *ds.Query) (*ds.QueryPlan, error) {
+	fq, err := q.Finalize()
+	switch {
+	case err == ds.ErrNullQuery:
+		return &ds.QueryPlan{Null: true}, nil
+	case err != nil:
+		return nil, err
+	}
+	idx, head := d.data.getQuerySnaps(!fq.EventuallyConsistent())
+	plan, err := explainQuery(fq, d.kc, false, idx, head)
+	if d.data.maybeAutoIndex(err) {
+		idx, head = d.data.getQuerySnaps(!fq.EventuallyConsistent())
+		plan, err = explainQuery(fq, d.kc, false, idx, head)
+	}
+	return plan, err
+}
+
+func (d *Datastore) GetTestable() ds.Testable { return d }
 
 ////////////////////////////////// txnDsImpl ///////////////////////////////////
 
@@ -202,6 +279,9 @@ func (d *txnDsImpl) AllocateIDs(keys []*ds.Key, cb ds.NewKeyCB) error {
 }
 
 func (d *txnDsImpl) PutMulti(keys []*ds.Key, vals []ds.PropertyMap, cb ds.NewKeyCB) error {
+	if err := d.data.parent.checkEntityLimits(vals); err != nil {
+		return err
+	}
 	return d.data.run(func() error {
 		d.data.putMulti(keys, vals, cb)
 		return nil
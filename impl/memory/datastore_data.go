@@ -71,6 +71,10 @@ type dataStoreData struct {
 	// no way to expose them.
 	showSpecialProps bool
 
+	// true means that Put will reject entities which exceed the production
+	// datastore's size limits (see checkEntityLimits).
+	enforceEntityLimits bool
+
 	// constraints is the fake datastore constraints. By default, this will match
 	// the Constraints of the "impl/prod" datastore.
 	constraints ds.Constraints
@@ -149,6 +153,12 @@ func (d *dataStoreData) getDisableSpecialEntities() bool {
 	return d.disableSpecialEntities
 }
 
+func (d *dataStoreData) setEnforceEntityLimits(enable bool) {
+	d.rwlock.Lock()
+	defer d.rwlock.Unlock()
+	d.enforceEntityLimits = enable
+}
+
 func (d *dataStoreData) setShowSpecialProperties(show bool) {
 	d.rwlock.Lock()
 	defer d.rwlock.Unlock()
@@ -367,6 +377,56 @@ func (d *dataStoreData) fixKey(key *ds.Key) (*ds.Key, error) {
 	return key, nil
 }
 
+const (
+	// maxEntitySize is the largest entity (as estimated by
+	// PropertyMap.EstimateSize) that the production datastore accepts.
+	maxEntitySize = 1048572
+
+	// maxIndexedValueSize is the longest string or []byte value that the
+	// production datastore accepts in an indexed property.
+	maxIndexedValueSize = 1500
+)
+
+// checkEntityLimits returns an error if enforceEntityLimits is set and any of
+// vals exceeds the production datastore's size limits. Like the production
+// datastore, a single bad entity rejects the entire batch.
+func (d *dataStoreData) checkEntityLimits(vals []ds.PropertyMap) error {
+	d.rwlock.RLock()
+	enforce := d.enforceEntityLimits
+	d.rwlock.RUnlock()
+	if !enforce {
+		return nil
+	}
+
+	for i, pm := range vals {
+		if size := pm.EstimateSize(); size > maxEntitySize {
+			return fmt.Errorf("gae/memory: entity %d is too big (%d > %d bytes)", i, size, maxEntitySize)
+		}
+		for name := range pm {
+			if strings.HasPrefix(name, "$") {
+				continue
+			}
+			for _, p := range pm.Slice(name) {
+				if p.IndexSetting() == ds.NoIndex {
+					continue
+				}
+				n := 0
+				switch v := p.Value().(type) {
+				case string:
+					n = len(v)
+				case []byte:
+					n = len(v)
+				}
+				if n > maxIndexedValueSize {
+					return fmt.Errorf("gae/memory: indexed property %q of entity %d is longer than %d bytes",
+						name, i, maxIndexedValueSize)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (d *dataStoreData) putMulti(keys []*ds.Key, vals []ds.PropertyMap, cb ds.NewKeyCB, lockedAlready bool) error {
 	ns := keys[0].Namespace()
 
@@ -80,6 +80,10 @@ type indexDefinitionSortable struct {
 	// (tag=1, tag=2) is a perfectly valid query).
 	eqFilts []ds.IndexColumn
 	coll    memCollection
+
+	// def is the index which coll holds. It's nil for the primary entity table
+	// (kindless queries).
+	def *ds.IndexDefinition
 }
 
 func (i *indexDefinitionSortable) hasAncestor() bool {
@@ -226,7 +230,7 @@ func (idxs *indexDefinitionSortableSlice) maybeAddDefinition(q *reducedQuery, s
 			}
 		}
 	}
-	toAdd := indexDefinitionSortable{coll: coll, eqFilts: eqFilts}
+	toAdd := indexDefinitionSortable{coll: coll, eqFilts: eqFilts, def: id}
 	if perfect {
 		*idxs = indexDefinitionSortableSlice{toAdd}
 	} else {
@@ -337,6 +341,7 @@ func getRelevantIndexes(q *reducedQuery, s memStore) (indexDefinitionSortableSli
 func generate(q *reducedQuery, idx *indexDefinitionSortable, c *constraints) *iterDefinition {
 	def := &iterDefinition{
 		c:     idx.coll,
+		def:   idx.def,
 		start: q.start,
 		end:   q.end,
 	}
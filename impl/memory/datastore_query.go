@@ -117,7 +117,7 @@ func numComponents(fq *ds.FinalizedQuery) int {
 // it is nil.
 //
 // NOTE: if fq specifies a descending sort order for the inequality, the bounds
-// will be inverted, incremented, and flipped.
+// will be inverted and swapped.
 func GetBinaryBounds(fq *ds.FinalizedQuery) (lower, upper []byte) {
 	// Pick up the start/end range from the inequalities, if any.
 	//
@@ -126,44 +126,57 @@ func GetBinaryBounds(fq *ds.FinalizedQuery) (lower, upper []byte) {
 	// contained in the query if they use the > or <= operators.
 	if ineqProp := fq.IneqFilterProp(); ineqProp != "" {
 		_, startOp, startV := fq.IneqFilterLow()
-		if startOp != "" {
-			lower = serialize.ToBytes(startV)
-			if startOp == ">" {
-				lower = increment(lower)
-			}
-		}
-
 		_, endOp, endV := fq.IneqFilterHigh()
-		if endOp != "" {
-			upper = serialize.ToBytes(endV)
-			if endOp == "<=" {
-				upper = increment(upper)
+
+		if !fq.Orders()[0].Descending {
+			if startOp != "" {
+				lower = indexValue(startV, false)
+				if startOp == ">" {
+					lower = increment(lower)
+				}
+			}
+			if endOp != "" {
+				upper = indexValue(endV, false)
+				if endOp == "<=" {
+					upper = increment(upper)
+				}
 			}
+			return
 		}
 
 		// The inequality is specified in natural (ascending) order in the query's
-		// Filter syntax, but the order information may indicate to use a descending
-		// index column for it. If that's the case, then we must invert, swap and
-		// increment the inequality endpoints.
-		//
-		// Invert so that the desired numbers are represented correctly in the index.
-		// Swap so that our iterators still go from >= start to < end.
-		// Increment so that >= and < get correctly bounded (since the iterator is
-		// still using natrual bytes ordering)
-		if fq.Orders()[0].Descending {
-			hi, lo := []byte(nil), []byte(nil)
-			if len(lower) > 0 {
-				lo = increment(serialize.Invert(lower))
+		// Filter syntax, but the order information indicates to use a descending
+		// index column for it. Descending columns store inverted values, so the
+		// high end of the filter becomes the low end of the index range, and
+		// vice versa.
+		if endOp != "" {
+			lower = indexValue(endV, true)
+			if endOp == "<" {
+				lower = increment(lower)
 			}
-			if len(upper) > 0 {
-				hi = increment(serialize.Invert(upper))
+		}
+		if startOp != "" {
+			upper = indexValue(startV, true)
+			if startOp == ">=" {
+				upper = increment(upper)
 			}
-			upper, lower = lo, hi
 		}
 	}
 	return
 }
 
+// indexValue returns the index encoding of p, as it would appear in an
+// ascending (or, if descending is true, a descending) index column.
+func indexValue(p ds.Property, descending bool) []byte {
+	buf := bytes.Buffer{}
+	write := serialize.WriteIndexValue
+	if descending {
+		write = serialize.WriteIndexValueInverted
+	}
+	memoryCorruption(write(&buf, p))
+	return buf.Bytes()
+}
+
 func reduce(fq *ds.FinalizedQuery, kc ds.KeyContext, isTxn bool) (*reducedQuery, error) {
 	if err := fq.Valid(kc); err != nil {
 		return nil, err
@@ -189,7 +202,7 @@ func reduce(fq *ds.FinalizedQuery, kc ds.KeyContext, isTxn bool) (*reducedQuery,
 	for prop, vals := range eqFilts {
 		sVals := stringset.New(len(vals))
 		for _, v := range vals {
-			sVals.Add(string(serialize.ToBytes(v)))
+			sVals.Add(string(indexValue(v, false)))
 		}
 		ret.eqFilters[prop] = sVals
 	}
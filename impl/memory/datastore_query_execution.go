@@ -310,3 +310,72 @@ func executeQuery(fq *ds.FinalizedQuery, kc ds.KeyContext, isTxn bool, idx, head
 			getCursorFn(suffix))
 	})
 }
+
+// explainQuery describes how executeQuery would service fq, without invoking
+// any callbacks.
+func explainQuery(fq *ds.FinalizedQuery, kc ds.KeyContext, isTxn bool, idx, head memStore) (*ds.QueryPlan, error) {
+	plan := &ds.QueryPlan{}
+
+	rq, err := reduce(fq, kc, isTxn)
+	if err == ds.ErrNullQuery {
+		plan.Null = true
+		return plan, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if rq.kind == "__namespace__" {
+		// mirrors the filter checks in executeNamespaceQuery.
+		if len(fq.EqFilters()) > 0 || len(fq.Project()) > 0 || len(fq.Orders()) > 1 ||
+			!(fq.IneqFilterProp() == "" || fq.IneqFilterProp() == "__key__") {
+			plan.Null = true
+			return plan, nil
+		}
+		plan.Scans = []ds.QueryScan{{
+			Type: ds.NamespaceScan,
+			Rows: len(namespaces(head)),
+		}}
+		plan.EstimatedRows = plan.Scans[0].Rows
+		return plan, nil
+	}
+
+	defs, err := getIndexes(rq, idx)
+	if err == ds.ErrNullQuery {
+		plan.Null = true
+		return plan, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	plan.Scans = make([]ds.QueryScan, len(defs))
+	for i, def := range defs {
+		scan := &plan.Scans[i]
+		scan.Prefix = def.prefix
+		scan.Start = def.start
+		scan.End = def.end
+
+		switch {
+		case def.def == nil:
+			scan.Type = ds.KindlessScan
+		case !def.def.Builtin():
+			scan.Type = ds.CompositeIndexScan
+		case len(def.def.SortBy) == 0:
+			scan.Type = ds.KindScan
+		default:
+			scan.Type = ds.BuiltinIndexScan
+		}
+		if def.def != nil {
+			scan.Index = def.def.Normalize()
+		}
+
+		it := def.mkIter()
+		for it.next() != nil {
+			scan.Rows++
+		}
+		plan.EstimatedRows += scan.Rows
+	}
+	plan.Merged = len(plan.Scans) > 1
+	return plan, nil
+}
@@ -22,6 +22,7 @@ import (
 
 	"go.chromium.org/gae/service/blobstore"
 	ds "go.chromium.org/gae/service/datastore"
+	"go.chromium.org/gae/service/datastore/serialize"
 	"go.chromium.org/gae/service/info"
 	"golang.org/x/net/context"
 
@@ -701,3 +702,131 @@ func shouldBeSuccessful(actual interface{}, expected ...interface{}) string {
 	}
 	return fmt.Sprintf("expected success value, not %v", v)
 }
+
+func TestExplainQuery(t *testing.T) {
+	t.Parallel()
+
+	Convey("ExplainQuery", t, func() {
+		c, err := info.Namespace(Use(context.Background()), "ns")
+		So(err, ShouldBeNil)
+
+		testing := ds.GetTestable(c)
+		testing.Consistent(true)
+
+		explainer, ok := testing.(ds.QueryExplainer)
+		So(ok, ShouldBeTrue)
+
+		So(ds.Put(c, []ds.PropertyMap{
+			pmap("$key", key("Kind", 1), Next,
+				"Val", 1, 2, 3, Next,
+				"Extra", "hello"),
+			pmap("$key", key("Kind", 2), Next,
+				"Val", 2, 3, 9, Next,
+				"Extra", "ace", "hello"),
+			pmap("$key", key("Kind", 3), Next,
+				"Val", 1, Next,
+				"Extra", "zebra"),
+			pmap("$key", key("Other", 1), Next,
+				"Val", 1),
+		}), shouldBeSuccessful)
+
+		Convey("kind-only queries use the Kind index", func() {
+			plan, err := explainer.ExplainQuery(nq("Kind"))
+			So(err, shouldBeSuccessful)
+			So(plan.Null, ShouldBeFalse)
+			So(plan.Merged, ShouldBeFalse)
+			So(plan.Scans, ShouldHaveLength, 1)
+			So(plan.Scans[0].Type, ShouldEqual, ds.KindScan)
+			So(plan.Scans[0].Index, ShouldResemble, indx("Kind").Normalize())
+			So(plan.Scans[0].Rows, ShouldEqual, 3)
+			So(plan.EstimatedRows, ShouldEqual, 3)
+		})
+
+		Convey("kindless queries scan the entity table", func() {
+			plan, err := explainer.ExplainQuery(nq(""))
+			So(err, shouldBeSuccessful)
+			So(plan.Scans, ShouldHaveLength, 1)
+			So(plan.Scans[0].Type, ShouldEqual, ds.KindlessScan)
+			So(plan.Scans[0].Index, ShouldBeNil)
+			// 4 entities, plus 4 __entity_group__ entities.
+			So(plan.EstimatedRows, ShouldEqual, 8)
+		})
+
+		Convey("a single equality filter uses a builtin index", func() {
+			plan, err := explainer.ExplainQuery(nq("Kind").Eq("Val", 1))
+			So(err, shouldBeSuccessful)
+			So(plan.Merged, ShouldBeFalse)
+			So(plan.Scans, ShouldHaveLength, 1)
+			So(plan.Scans[0].Type, ShouldEqual, ds.BuiltinIndexScan)
+			So(plan.Scans[0].Index, ShouldResemble, indx("Kind", "Val").Normalize())
+			So(plan.Scans[0].Prefix, ShouldResemble, serialize.ToBytes(prop(1)))
+			So(plan.Scans[0].Rows, ShouldEqual, 2)
+		})
+
+		Convey("inequalities are encoded in the scan bounds", func() {
+			plan, err := explainer.ExplainQuery(nq("Kind").Gt("Val", 2).Lte("Val", 3))
+			So(err, shouldBeSuccessful)
+			So(plan.Scans, ShouldHaveLength, 1)
+			So(plan.Scans[0].Type, ShouldEqual, ds.BuiltinIndexScan)
+			So(plan.Scans[0].Start, ShouldResemble, increment(serialize.ToBytes(prop(2))))
+			So(plan.Scans[0].End, ShouldResemble, increment(serialize.ToBytes(prop(3))))
+			So(plan.Scans[0].Rows, ShouldEqual, 2)
+		})
+
+		Convey("multiple equality filters merge builtin indexes", func() {
+			plan, err := explainer.ExplainQuery(nq("Kind").Eq("Val", 1).Eq("Extra", "hello"))
+			So(err, shouldBeSuccessful)
+			So(plan.Merged, ShouldBeTrue)
+			So(plan.Scans, ShouldHaveLength, 2)
+			for _, scan := range plan.Scans {
+				So(scan.Type, ShouldEqual, ds.BuiltinIndexScan)
+			}
+			So(plan.EstimatedRows, ShouldEqual, 4)
+		})
+
+		Convey("equality filters with a sort order use a composite index", func() {
+			testing.AddIndexes(indx("Kind", "Extra", "Val"))
+
+			plan, err := explainer.ExplainQuery(nq("Kind").Eq("Extra", "hello").Order("Val"))
+			So(err, shouldBeSuccessful)
+			So(plan.Merged, ShouldBeFalse)
+			So(plan.Scans, ShouldHaveLength, 1)
+			So(plan.Scans[0].Type, ShouldEqual, ds.CompositeIndexScan)
+			So(plan.Scans[0].Index, ShouldResemble, indx("Kind", "Extra", "Val").Normalize())
+			So(plan.Scans[0].Prefix, ShouldResemble, serialize.ToBytes(prop("hello")))
+			So(plan.Scans[0].Rows, ShouldEqual, 6)
+		})
+
+		Convey("impossible queries are null", func() {
+			plan, err := explainer.ExplainQuery(nq("Kind").Gt("Val", 5).Lt("Val", 3))
+			So(err, shouldBeSuccessful)
+			So(plan.Null, ShouldBeTrue)
+			So(plan.Scans, ShouldBeEmpty)
+
+			plan, err = explainer.ExplainQuery(nq("Missing").Eq("Val", 1))
+			So(err, shouldBeSuccessful)
+			So(plan.Null, ShouldBeTrue)
+		})
+
+		Convey("namespace queries", func() {
+			plan, err := explainer.ExplainQuery(nq("__namespace__"))
+			So(err, shouldBeSuccessful)
+			So(plan.Scans, ShouldHaveLength, 1)
+			So(plan.Scans[0].Type, ShouldEqual, ds.NamespaceScan)
+			So(plan.EstimatedRows, ShouldEqual, 1)
+		})
+
+		Convey("missing indexes", func() {
+			q := nq("Kind").Gt("Val", 2).Order("Val", "Extra")
+
+			_, err := explainer.ExplainQuery(q)
+			So(err, ShouldErrLike, "Insufficient indexes")
+
+			testing.AutoIndex(true)
+			plan, err := explainer.ExplainQuery(q)
+			So(err, shouldBeSuccessful)
+			So(plan.Scans, ShouldHaveLength, 1)
+			So(plan.Scans[0].Type, ShouldEqual, ds.CompositeIndexScan)
+		})
+	})
+}
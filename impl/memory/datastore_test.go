@@ -18,6 +18,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -738,7 +739,7 @@ func TestCompoundIndexes(t *testing.T) {
 		}
 
 		c := Use(context.Background())
-		t := ds.GetTestable(c).(*dsImpl)
+		t := ds.GetTestable(c).(*Datastore)
 		head := t.data.head
 
 		So(ds.Put(c, &Model{1, []string{"hello", "world"}, []int64{10, 11}}), ShouldBeNil)
@@ -790,6 +791,37 @@ func TestDefaultTimeField(t *testing.T) {
 	})
 }
 
+func TestKeyOrder(t *testing.T) {
+	t.Parallel()
+
+	Convey("Query results are in ds.KeyLess order", t, func() {
+		c := Use(context.Background())
+
+		keys := []*ds.Key{
+			ds.MakeKey(c, "Thing", "b"),
+			ds.MakeKey(c, "Parent", 2, "Thing", 1),
+			ds.MakeKey(c, "Thing", 10),
+			ds.MakeKey(c, "Parent", "a", "Thing", "a"),
+			ds.MakeKey(c, "Thing", "10"),
+			ds.MakeKey(c, "Parent", 2, "Thing", "a"),
+			ds.MakeKey(c, "Thing", 2),
+			ds.MakeKey(c, "Parent", 1, "Thing", 3),
+		}
+		pms := make([]ds.PropertyMap, len(keys))
+		for i, k := range keys {
+			pms[i] = ds.PropertyMap{"$key": ds.MkPropertyNI(k)}
+		}
+		So(ds.Put(c, pms), ShouldBeNil)
+		ds.GetTestable(c).CatchupIndexes()
+
+		var got []*ds.Key
+		So(ds.GetAll(c, ds.NewQuery("Thing"), &got), ShouldBeNil)
+
+		sort.Slice(keys, func(i, j int) bool { return ds.KeyLess(keys[i], keys[j]) })
+		So(got, ShouldResemble, keys)
+	})
+}
+
 func TestNewDatastore(t *testing.T) {
 	t.Parallel()
 
@@ -875,6 +907,210 @@ func TestAddIndexes(t *testing.T) {
 	})
 }
 
+type onDeleteFilter struct {
+	ds.RawInterface
+
+	cb func()
+}
+
+func (f *onDeleteFilter) DeleteMulti(keys []*ds.Key, cb ds.DeleteMultiCB) error {
+	if f.cb != nil {
+		f.cb()
+	}
+	return f.RawInterface.DeleteMulti(keys, cb)
+}
+
+func TestDeleteAll(t *testing.T) {
+	t.Parallel()
+
+	Convey("Test DeleteAll", t, func() {
+		c := Use(context.Background())
+		ds.GetTestable(c).Consistent(true)
+
+		foos := make([]*Foo, 10)
+		for i := range foos {
+			foos[i] = &Foo{ID: int64(i + 10), Val: 1}
+		}
+		So(ds.Put(c, foos, &Foo{ID: 100, Val: 2}), ShouldBeNil)
+
+		remaining := func() (ids []int64) {
+			var keys []*ds.Key
+			So(ds.GetAll(c, ds.NewQuery("Foo"), &keys), ShouldBeNil)
+			for _, k := range keys {
+				ids = append(ids, k.IntID())
+			}
+			return
+		}
+
+		Convey("deletes the matching entities", func() {
+			n, err := ds.DeleteAll(c, ds.NewQuery("Foo").Eq("Val", 1), 3)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 10)
+			So(remaining(), ShouldResemble, []int64{100})
+		})
+
+		Convey("refuses projection queries", func() {
+			_, err := ds.DeleteAll(c, ds.NewQuery("Foo").Project("Val"), 3)
+			So(err, ShouldErrLike, "does not support projection")
+			So(remaining(), ShouldHaveLength, 11)
+		})
+
+		Convey("handles entities added during the run", func() {
+			// Add matching entities on either side of the first batch's cursor, as
+			// well as a non-matching entity, during the first DeleteMulti.
+			once := sync.Once{}
+			fc := ds.AddRawFilters(c, func(ic context.Context, raw ds.RawInterface) ds.RawInterface {
+				return &onDeleteFilter{raw, func() {
+					once.Do(func() {
+						So(ds.Put(c, &Foo{ID: 5, Val: 1}, &Foo{ID: 50, Val: 1}, &Foo{ID: 51, Val: 2}), ShouldBeNil)
+					})
+				}}
+			})
+
+			n, err := ds.DeleteAll(fc, ds.NewQuery("Foo").Eq("Val", 1), 3)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 11)
+
+			// ID 5 sorts before the cursor, so it's not seen until the next run.
+			So(remaining(), ShouldResemble, []int64{5, 51, 100})
+
+			n, err = ds.DeleteAll(c, ds.NewQuery("Foo").Eq("Val", 1), 3)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 1)
+			So(remaining(), ShouldResemble, []int64{51, 100})
+		})
+	})
+}
+
+func TestGetOrInsert(t *testing.T) {
+	t.Parallel()
+
+	Convey("Test GetOrInsert", t, func() {
+		c := Use(context.Background())
+
+		Convey("creates a missing entity", func() {
+			f := &Foo{ID: 1}
+			created, err := ds.GetOrInsert(c, f, func(obj interface{}) error {
+				So(obj, ShouldEqual, f)
+				obj.(*Foo).Val = 10
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(created, ShouldBeTrue)
+
+			got := &Foo{ID: 1}
+			So(ds.Get(c, got), ShouldBeNil)
+			So(got.Val, ShouldEqual, 10)
+
+			Convey("and loads it afterwards", func() {
+				f := &Foo{ID: 1}
+				created, err := ds.GetOrInsert(c, f, func(interface{}) error {
+					panic("init called for an existing entity")
+				})
+				So(err, ShouldBeNil)
+				So(created, ShouldBeFalse)
+				So(f.Val, ShouldEqual, 10)
+			})
+		})
+
+		Convey("works with a nil init and a PropertyMap", func() {
+			pm := ds.PropertyMap{
+				"$key":  ds.MkPropertyNI(ds.MakeKey(c, "Thing", "single")),
+				"Value": ds.MkProperty(1),
+			}
+			created, err := ds.GetOrInsert(c, pm, nil)
+			So(err, ShouldBeNil)
+			So(created, ShouldBeTrue)
+
+			pm = ds.PropertyMap{"$key": ds.MkPropertyNI(ds.MakeKey(c, "Thing", "single"))}
+			created, err = ds.GetOrInsert(c, pm, nil)
+			So(err, ShouldBeNil)
+			So(created, ShouldBeFalse)
+			So(pm.Slice("Value"), ShouldResemble, ds.PropertySlice{ds.MkProperty(1)})
+		})
+
+		Convey("rejects incomplete keys", func() {
+			_, err := ds.GetOrInsert(c, &Foo{}, nil)
+			So(ds.IsErrInvalidKey(err), ShouldBeTrue)
+		})
+
+		Convey("aborts if init fails", func() {
+			created, err := ds.GetOrInsert(c, &Foo{ID: 1}, func(interface{}) error {
+				return errors.New("nope")
+			})
+			So(err, ShouldErrLike, "nope")
+			So(created, ShouldBeFalse)
+			So(ds.Get(c, &Foo{ID: 1}), ShouldEqual, ds.ErrNoSuchEntity)
+		})
+
+		Convey("restores the object between retries", func() {
+			tst := ds.GetTestable(c)
+			tst.SetTransactionRetryCount(2)
+			defer tst.SetTransactionRetryCount(0)
+
+			f := &Foo{ID: 1}
+			calls := 0
+			created, err := ds.GetOrInsert(c, f, func(obj interface{}) error {
+				calls++
+				f := obj.(*Foo)
+				So(f.Multi, ShouldBeEmpty)
+				f.Multi = []string{"attempt"}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(created, ShouldBeTrue)
+			So(calls, ShouldEqual, 3)
+			So(f.Multi, ShouldResemble, []string{"attempt"})
+		})
+
+		Convey("participates in an existing transaction", func() {
+			So(ds.RunInTransaction(c, func(c context.Context) error {
+				created, err := ds.GetOrInsert(c, &Foo{ID: 1}, nil)
+				So(created, ShouldBeTrue)
+				return err
+			}, nil), ShouldBeNil)
+			So(ds.Get(c, &Foo{ID: 1}), ShouldBeNil)
+		})
+
+		Convey("only one concurrent creation wins", func() {
+			for round := 0; round < 100; round++ {
+				barrier := make(chan struct{})
+				wg := sync.WaitGroup{}
+				var creations, failures int64
+
+				for track := 0; track < 5; track++ {
+					wg.Add(1)
+					go func(track int) {
+						defer wg.Done()
+						<-barrier
+
+						f := &Foo{ID: int64(round + 1)}
+						created, err := ds.GetOrInsert(c, f, func(obj interface{}) error {
+							obj.(*Foo).Val = track
+							return nil
+						})
+						switch {
+						case err == ds.ErrConcurrentTransaction:
+							atomic.AddInt64(&failures, 1)
+						case err != nil:
+							panic(err)
+						case created:
+							atomic.AddInt64(&creations, 1)
+						}
+					}(track)
+				}
+
+				close(barrier)
+				wg.Wait()
+
+				if creations != 1 { // don't spam convey assertions
+					So(creations, ShouldEqual, 1)
+				}
+			}
+		})
+	})
+}
+
 func TestConcurrentTxn(t *testing.T) {
 	t.Parallel()
 
@@ -929,3 +1165,64 @@ func TestConcurrentTxn(t *testing.T) {
 		}
 	})
 }
+
+func TestEntityLimits(t *testing.T) {
+	t.Parallel()
+
+	Convey("Test entity size limits", t, func() {
+		c := Use(context.Background())
+
+		k1 := ds.MakeKey(c, "Foo", 1)
+		k2 := ds.MakeKey(c, "Foo", 2)
+		ok := pmap("$key", k1, Next, "Val", 1, Next)
+
+		exists := func() ds.BoolList {
+			ex, err := ds.Exists(c, []*ds.Key{k1, k2})
+			So(err, ShouldBeNil)
+			return ex.List(0)
+		}
+
+		big := ds.PropertyMap{
+			"$key": ds.MkPropertyNI(k2),
+			"Blob": ds.MkPropertyNI(make([]byte, maxEntitySize)),
+		}
+
+		Convey("are not enforced by default", func() {
+			So(ds.Put(c, ok, big), ShouldBeNil)
+			So(exists(), ShouldResemble, ds.BoolList{true, true})
+		})
+
+		ds.GetTestable(c).(*Datastore).EnforceEntityLimits(true)
+
+		Convey("an oversized entity rejects the batch", func() {
+			So(ds.Put(c, ok, big), ShouldErrLike, "entity 1 is too big")
+			So(exists(), ShouldResemble, ds.BoolList{false, false})
+		})
+
+		Convey("a long indexed string rejects the batch", func() {
+			long := ds.PropertyMap{
+				"$key": ds.MkPropertyNI(k2),
+				"Str":  ds.MkProperty(string(make([]byte, maxIndexedValueSize+1))),
+			}
+			So(ds.Put(c, ok, long), ShouldErrLike, `indexed property "Str" of entity 1 is longer than 1500 bytes`)
+			So(exists(), ShouldResemble, ds.BoolList{false, false})
+
+			Convey("unless it's unindexed", func() {
+				long["Str"] = ds.MkPropertyNI(string(make([]byte, maxIndexedValueSize+1)))
+				So(ds.Put(c, ok, long), ShouldBeNil)
+				So(exists(), ShouldResemble, ds.BoolList{true, true})
+			})
+		})
+
+		Convey("a long indexed []byte rejects the batch in a transaction", func() {
+			long := ds.PropertyMap{
+				"$key":  ds.MkPropertyNI(k2),
+				"Bytes": ds.MkProperty(make([]byte, maxIndexedValueSize+1)),
+			}
+			So(ds.RunInTransaction(c, func(c context.Context) error {
+				return ds.Put(c, ok, long)
+			}, nil), ShouldErrLike, `indexed property "Bytes" of entity 1 is longer than 1500 bytes`)
+			So(exists(), ShouldResemble, ds.BoolList{false, false})
+		})
+	})
+}
@@ -16,6 +16,15 @@
 // backs to local memory ONLY. This is useful for unittesting, and is also used
 // for the nested-transaction filter implementation.
 //
+// Standalone Services
+//
+// The datastore, memcache and taskqueue emulators can also be constructed
+// without a context.Context with NewStandaloneDatastore, NewStandaloneMemcache
+// and NewStandaloneTaskQueue. These implement the raw service interfaces
+// directly, which is convenient for benchmarks, fuzzers, or embedding the
+// emulator in other test harnesses. They're the same Datastore, Memcache and
+// TaskQueue types that UseWithAppID installs in the context.
+//
 // Debug EnvVars
 //
 // To debug backend store memory access for a binary that uses this memory
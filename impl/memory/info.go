@@ -115,6 +115,18 @@ func (gi *giImpl) RequestID() string {
 	return curGID(gi.c).requestID
 }
 
+// IsOverQuota always returns false, since the in-memory services never enforce
+// quotas.
+func (gi *giImpl) IsOverQuota(error) bool {
+	return false
+}
+
+// IsTimeoutError always returns false, since the in-memory services never
+// time out.
+func (gi *giImpl) IsTimeoutError(error) bool {
+	return false
+}
+
 func (gi *giImpl) GetTestable() info.Testable {
 	return gi
 }
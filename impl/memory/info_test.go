@@ -15,6 +15,7 @@
 package memory
 
 import (
+	"errors"
 	"testing"
 
 	"go.chromium.org/gae/service/info"
@@ -44,3 +45,14 @@ func TestMustNamespace(t *testing.T) {
 		So(info.RequestID(c), ShouldEqual, "override")
 	})
 }
+
+func TestErrorClassifiers(t *testing.T) {
+	t.Parallel()
+
+	Convey("Error classifiers never match", t, func() {
+		c := Use(context.Background())
+
+		So(info.IsOverQuota(c, errors.New("over quota")), ShouldBeFalse)
+		So(info.IsTimeoutError(c, context.DeadlineExceeded), ShouldBeFalse)
+	})
+}
@@ -95,6 +95,10 @@ type memcacheData struct {
 	stats mc.Statistics
 }
 
+func newMemcacheData() *memcacheData {
+	return &memcacheData{items: map[string]*mcDataItem{}}
+}
+
 func (m *memcacheData) mkDataItemLocked(now time.Time, i mc.Item) (ret *mcDataItem) {
 	m.casID++
 
@@ -156,14 +160,29 @@ func (m *memcacheData) retrieveLocked(now time.Time, key string) (*mcDataItem, e
 	return ret, nil
 }
 
-// memcacheImpl binds the current connection's memcache data to an
-// implementation of {gae.Memcache, gae.Testable}.
-type memcacheImpl struct {
+// Memcache is an in-memory memcache. It implements memcache.RawInterface
+// directly, so it can be used without installing it in a context.Context.
+//
+// UseWithAppID installs one Memcache per namespace in the context, and the
+// memcache service functions operate on copies of them which are bound to
+// their Context.
+type Memcache struct {
 	data *memcacheData
 	ctx  context.Context
 }
 
-var _ mc.RawInterface = (*memcacheImpl)(nil)
+var _ mc.RawInterface = (*Memcache)(nil)
+
+// NewStandaloneMemcache returns a new, empty Memcache.
+func NewStandaloneMemcache() *Memcache {
+	return &Memcache{newMemcacheData(), context.Background()}
+}
+
+// bind returns a copy of m which shares its data, but is bound to the Context
+// c.
+func (m *Memcache) bind(c context.Context) *Memcache {
+	return &Memcache{m.data, c}
+}
 
 // useMC adds a gae.Memcache implementation to context, accessible
 // by gae.GetMC(c)
@@ -171,27 +190,23 @@ func useMC(c context.Context) context.Context {
 	lck := sync.Mutex{}
 	// TODO(riannucci): just use namespace for automatic key prefixing. Flush
 	// actually wipes the ENTIRE memcache, regardless of namespace.
-	mcdMap := map[string]*memcacheData{}
+	mcMap := map[string]*Memcache{}
 
 	return mc.SetRawFactory(c, func(ic context.Context) mc.RawInterface {
 		lck.Lock()
 		defer lck.Unlock()
 
 		ns := info.GetNamespace(ic)
-		mcd, ok := mcdMap[ns]
+		m, ok := mcMap[ns]
 		if !ok {
-			mcd = &memcacheData{items: map[string]*mcDataItem{}}
-			mcdMap[ns] = mcd
-		}
-
-		return &memcacheImpl{
-			mcd,
-			ic,
+			m = NewStandaloneMemcache()
+			mcMap[ns] = m
 		}
+		return m.bind(ic)
 	})
 }
 
-func (m *memcacheImpl) NewItem(key string) mc.Item {
+func (m *Memcache) NewItem(key string) mc.Item {
 	return &mcItem{key: key}
 }
 
@@ -211,7 +226,7 @@ func doCBs(items []mc.Item, cb mc.RawCB, inner func(mc.Item) error) {
 	}
 }
 
-func (m *memcacheImpl) AddMulti(items []mc.Item, cb mc.RawCB) error {
+func (m *Memcache) AddMulti(items []mc.Item, cb mc.RawCB) error {
 	now := clock.Now(m.ctx)
 	doCBs(items, cb, func(itm mc.Item) error {
 		m.data.lock.Lock()
@@ -225,7 +240,7 @@ func (m *memcacheImpl) AddMulti(items []mc.Item, cb mc.RawCB) error {
 	return nil
 }
 
-func (m *memcacheImpl) CompareAndSwapMulti(items []mc.Item, cb mc.RawCB) error {
+func (m *Memcache) CompareAndSwapMulti(items []mc.Item, cb mc.RawCB) error {
 	now := clock.Now(m.ctx)
 	doCBs(items, cb, func(itm mc.Item) error {
 		m.data.lock.Lock()
@@ -249,7 +264,7 @@ func (m *memcacheImpl) CompareAndSwapMulti(items []mc.Item, cb mc.RawCB) error {
 	return nil
 }
 
-func (m *memcacheImpl) SetMulti(items []mc.Item, cb mc.RawCB) error {
+func (m *Memcache) SetMulti(items []mc.Item, cb mc.RawCB) error {
 	now := clock.Now(m.ctx)
 	doCBs(items, cb, func(itm mc.Item) error {
 		m.data.lock.Lock()
@@ -260,7 +275,7 @@ func (m *memcacheImpl) SetMulti(items []mc.Item, cb mc.RawCB) error {
 	return nil
 }
 
-func (m *memcacheImpl) GetMulti(keys []string, cb mc.RawItemCB) error {
+func (m *Memcache) GetMulti(keys []string, cb mc.RawItemCB) error {
 	now := clock.Now(m.ctx)
 
 	itms := make([]mc.Item, len(keys))
@@ -285,7 +300,7 @@ func (m *memcacheImpl) GetMulti(keys []string, cb mc.RawItemCB) error {
 	return nil
 }
 
-func (m *memcacheImpl) DeleteMulti(keys []string, cb mc.RawCB) error {
+func (m *Memcache) DeleteMulti(keys []string, cb mc.RawCB) error {
 	now := clock.Now(m.ctx)
 
 	errs := make([]error, len(keys))
@@ -310,7 +325,7 @@ func (m *memcacheImpl) DeleteMulti(keys []string, cb mc.RawCB) error {
 	return nil
 }
 
-func (m *memcacheImpl) Flush() error {
+func (m *Memcache) Flush() error {
 	m.data.lock.Lock()
 	defer m.data.lock.Unlock()
 
@@ -318,7 +333,7 @@ func (m *memcacheImpl) Flush() error {
 	return nil
 }
 
-func (m *memcacheImpl) Increment(key string, delta int64, initialValue *uint64) (uint64, error) {
+func (m *Memcache) Increment(key string, delta int64, initialValue *uint64) (uint64, error) {
 	now := clock.Now(m.ctx)
 
 	m.data.lock.Lock()
@@ -353,7 +368,7 @@ func (m *memcacheImpl) Increment(key string, delta int64, initialValue *uint64)
 	return cur, nil
 }
 
-func (m *memcacheImpl) Stats() (*mc.Statistics, error) {
+func (m *Memcache) Stats() (*mc.Statistics, error) {
 	m.data.lock.Lock()
 	defer m.data.lock.Unlock()
 
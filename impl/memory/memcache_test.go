@@ -203,7 +203,7 @@ func TestMemcache(t *testing.T) {
 			_, err = mc.GetKey(c, "wot")
 			So(err, ShouldErrLike, mc.ErrCacheMiss)
 
-			mci := mc.Raw(c).(*memcacheImpl)
+			mci := mc.Raw(c).(*Memcache)
 
 			stats, err := mc.Stats(c)
 			So(err, ShouldBeNil)
@@ -17,6 +17,7 @@ package memory
 import (
 	"bytes"
 
+	ds "go.chromium.org/gae/service/datastore"
 	"go.chromium.org/gae/service/datastore/serialize"
 )
 
@@ -24,6 +25,10 @@ type iterDefinition struct {
 	// The collection to iterate over
 	c memCollection
 
+	// The index which c holds, or nil if c is the primary entity table. This is
+	// only used to describe query plans.
+	def *ds.IndexDefinition
+
 	// The prefix to always assert for every row. A nil prefix matches every row.
 	prefix []byte
 
@@ -0,0 +1,153 @@
+// Copyright 2017 The LUCI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"testing"
+
+	ds "go.chromium.org/gae/service/datastore"
+	mc "go.chromium.org/gae/service/memcache"
+	tq "go.chromium.org/gae/service/taskqueue"
+
+	. "go.chromium.org/luci/common/testing/assertions"
+
+	"golang.org/x/net/context"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStandalone(t *testing.T) {
+	t.Parallel()
+
+	Convey("Standalone", t, func() {
+		Convey("Datastore", func() {
+			d := NewStandaloneDatastore("dev~aid")
+			kc := ds.KeyContext{AppID: "dev~aid"}
+
+			k := kc.NewKey("Thing", "", 1, nil)
+			So(d.PutMulti([]*ds.Key{k}, []ds.PropertyMap{pmap("Val", 10, Next)}, func(_ int, key *ds.Key, err error) error {
+				So(err, ShouldBeNil)
+				So(key, ShouldResemble, k)
+				return nil
+			}), ShouldBeNil)
+
+			Convey("can get", func() {
+				var got ds.PropertyMap
+				So(d.GetMulti([]*ds.Key{k}, nil, func(_ int, pm ds.PropertyMap, err error) error {
+					So(err, ShouldBeNil)
+					got = pm
+					return nil
+				}), ShouldBeNil)
+				So(got, ShouldResemble, pmap("Val", 10, Next))
+			})
+
+			Convey("can query", func() {
+				d.GetTestable().CatchupIndexes()
+
+				fq, err := ds.NewQuery("Thing").Gt("Val", 5).Finalize()
+				So(err, ShouldBeNil)
+
+				keys := []*ds.Key{}
+				So(d.Run(fq, func(key *ds.Key, _ ds.PropertyMap, _ ds.CursorCB) error {
+					keys = append(keys, key)
+					return nil
+				}), ShouldBeNil)
+				So(keys, ShouldResemble, []*ds.Key{k})
+			})
+
+			Convey("can run transactions", func() {
+				k2 := kc.NewKey("Thing", "", 2, nil)
+				So(d.RunInTransaction(func(c context.Context) error {
+					So(ds.CurrentTransaction(c), ShouldNotBeNil)
+					return ds.Put(c, pmap("$key", k2, Next, "Val", 20, Next))
+				}, nil), ShouldBeNil)
+
+				So(d.GetMulti([]*ds.Key{k2}, nil, func(_ int, pm ds.PropertyMap, err error) error {
+					So(err, ShouldBeNil)
+					So(pm, ShouldResemble, pmap("Val", 20, Next))
+					return nil
+				}), ShouldBeNil)
+			})
+
+			Convey("is independent of other instances", func() {
+				other := NewStandaloneDatastore("dev~aid")
+				So(other.GetMulti([]*ds.Key{k}, nil, func(_ int, _ ds.PropertyMap, err error) error {
+					So(err, ShouldEqual, ds.ErrNoSuchEntity)
+					return nil
+				}), ShouldBeNil)
+			})
+		})
+
+		Convey("Memcache", func() {
+			m := NewStandaloneMemcache()
+
+			itm := m.NewItem("key").SetValue([]byte("value"))
+			So(m.SetMulti([]mc.Item{itm}, func(err error) {
+				So(err, ShouldBeNil)
+			}), ShouldBeNil)
+
+			errs := []error{}
+			So(m.GetMulti([]string{"key", "missing"}, func(got mc.Item, err error) {
+				if err == nil {
+					So(got.Value(), ShouldResemble, []byte("value"))
+				}
+				errs = append(errs, err)
+			}), ShouldBeNil)
+			So(errs, ShouldResemble, []error{nil, mc.ErrCacheMiss})
+
+			stats, err := m.Stats()
+			So(err, ShouldBeNil)
+			So(stats.Hits, ShouldEqual, 1)
+			So(stats.Misses, ShouldEqual, 1)
+		})
+
+		Convey("TaskQueue", func() {
+			q := NewStandaloneTaskQueue()
+
+			So(q.AddMulti([]*tq.Task{{Name: "task", Path: "/hello"}}, "", func(task *tq.Task, err error) {
+				So(err, ShouldBeNil)
+				So(task.Name, ShouldEqual, "task")
+			}), ShouldBeNil)
+
+			sched := q.GetTestable().GetScheduledTasks()
+			So(sched["default"], ShouldContainKey, "task")
+
+			Convey("and rejects unknown queues", func() {
+				err := q.AddMulti([]*tq.Task{{Path: "/hello"}}, "missing", nil)
+				So(err, ShouldErrLike, "UNKNOWN_QUEUE")
+			})
+		})
+
+		Convey("are the types installed by Use", func() {
+			c := Use(context.Background())
+
+			So(ds.GetTestable(c), ShouldHaveSameTypeAs, &Datastore{})
+			So(mc.Raw(c), ShouldHaveSameTypeAs, &Memcache{})
+			So(tq.Raw(c), ShouldHaveSameTypeAs, &TaskQueue{})
+
+			Convey("and share their state across bound copies", func() {
+				k := ds.MakeKey(c, "Thing", 1)
+				So(ds.Put(c, pmap("$key", k, Next, "Val", 10, Next)), ShouldBeNil)
+
+				d := ds.GetTestable(c).(*Datastore)
+				So(d.GetMulti([]*ds.Key{k}, nil, func(_ int, pm ds.PropertyMap, err error) error {
+					So(err, ShouldBeNil)
+					So(pm, ShouldResemble, pmap("Val", 10, Next))
+					return nil
+				}), ShouldBeNil)
+			})
+		})
+	})
+}
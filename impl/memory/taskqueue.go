@@ -39,22 +39,50 @@ func useTQ(c context.Context) context.Context {
 		if isTxn {
 			return &taskqueueTxnImpl{tqd.(*txnTaskQueueData), ic, ns}
 		}
-		return &taskqueueImpl{tqd.(*taskQueueData), ic, ns}
+		return tqd.(*TaskQueue).bind(ic, ns)
 	})
 }
 
-//////////////////////////////// taskqueueImpl /////////////////////////////////
+/////////////////////////////////// TaskQueue //////////////////////////////////
 
-type taskqueueImpl struct {
+// TaskQueue is an in-memory task queue service. It implements
+// taskqueue.RawInterface directly, so it can be used without installing it in
+// a context.Context.
+//
+// UseWithAppID installs a TaskQueue in the context, and the taskqueue service
+// functions operate on copies of it which are bound to their Context and
+// namespace. All copies share the same underlying queues.
+type TaskQueue struct {
 	*taskQueueData
 
 	ctx context.Context
 	ns  string
 }
 
-var _ tq.RawInterface = (*taskqueueImpl)(nil)
+var _ tq.RawInterface = (*TaskQueue)(nil)
+var _ memContextObj = (*TaskQueue)(nil)
+
+// newTaskQueue returns a new TaskQueue with only the "default" queue, and the
+// same constraints as production. It isn't bound to any Context.
+func newTaskQueue() *TaskQueue {
+	return &TaskQueue{taskQueueData: newTaskQueueData()}
+}
+
+// NewStandaloneTaskQueue returns a new TaskQueue with only the "default"
+// queue, and the same constraints as production.
+//
+// Tasks are added in the default (empty) namespace.
+func NewStandaloneTaskQueue() *TaskQueue {
+	return newTaskQueue().bind(context.Background(), "")
+}
+
+// bind returns a copy of t which shares its queues, but is bound to the
+// Context c and the namespace ns.
+func (t *TaskQueue) bind(c context.Context, ns string) *TaskQueue {
+	return &TaskQueue{t.taskQueueData, c, ns}
+}
 
-func (t *taskqueueImpl) AddMulti(tasks []*tq.Task, queueName string, cb tq.RawTaskCB) error {
+func (t *TaskQueue) AddMulti(tasks []*tq.Task, queueName string, cb tq.RawTaskCB) error {
 	// Reject the entire batch if at least one task is bad. That's how prod API
 	// behaves too.
 	if err := checkManyTasks(tasks, false); err != nil {
@@ -85,7 +113,7 @@ func (t *taskqueueImpl) AddMulti(tasks []*tq.Task, queueName string, cb tq.RawTa
 	return nil
 }
 
-func (t *taskqueueImpl) DeleteMulti(tasks []*tq.Task, queueName string, cb tq.RawCB) error {
+func (t *TaskQueue) DeleteMulti(tasks []*tq.Task, queueName string, cb tq.RawCB) error {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
@@ -102,7 +130,7 @@ func (t *taskqueueImpl) DeleteMulti(tasks []*tq.Task, queueName string, cb tq.Ra
 	return nil
 }
 
-func (t *taskqueueImpl) Lease(maxTasks int, queueName string, leaseTime time.Duration) ([]*tq.Task, error) {
+func (t *TaskQueue) Lease(maxTasks int, queueName string, leaseTime time.Duration) ([]*tq.Task, error) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
@@ -114,7 +142,7 @@ func (t *taskqueueImpl) Lease(maxTasks int, queueName string, leaseTime time.Dur
 	return q.leaseTasks(clock.Now(t.ctx), maxTasks, leaseTime, false, "")
 }
 
-func (t *taskqueueImpl) LeaseByTag(maxTasks int, queueName string, leaseTime time.Duration, tag string) ([]*tq.Task, error) {
+func (t *TaskQueue) LeaseByTag(maxTasks int, queueName string, leaseTime time.Duration, tag string) ([]*tq.Task, error) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
@@ -126,7 +154,7 @@ func (t *taskqueueImpl) LeaseByTag(maxTasks int, queueName string, leaseTime tim
 	return q.leaseTasks(clock.Now(t.ctx), maxTasks, leaseTime, true, tag)
 }
 
-func (t *taskqueueImpl) ModifyLease(task *tq.Task, queueName string, leaseTime time.Duration) error {
+func (t *TaskQueue) ModifyLease(task *tq.Task, queueName string, leaseTime time.Duration) error {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
@@ -138,14 +166,14 @@ func (t *taskqueueImpl) ModifyLease(task *tq.Task, queueName string, leaseTime t
 	return q.modifyTaskLease(clock.Now(t.ctx), task, leaseTime)
 }
 
-func (t *taskqueueImpl) Purge(queueName string) error {
+func (t *TaskQueue) Purge(queueName string) error {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
 	return t.purgeLocked(queueName)
 }
 
-func (t *taskqueueImpl) Stats(queueNames []string, cb tq.RawStatsCB) error {
+func (t *TaskQueue) Stats(queueNames []string, cb tq.RawStatsCB) error {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
@@ -161,16 +189,16 @@ func (t *taskqueueImpl) Stats(queueNames []string, cb tq.RawStatsCB) error {
 	return nil
 }
 
-func (t *taskqueueImpl) SetConstraints(c *tq.Constraints) error {
+func (t *TaskQueue) SetConstraints(c *tq.Constraints) error {
 	t.setConstraints(c)
 	return nil
 }
 
-func (t *taskqueueImpl) Constraints() tq.Constraints {
+func (t *TaskQueue) Constraints() tq.Constraints {
 	return t.getConstraints()
 }
 
-func (t *taskqueueImpl) GetTestable() tq.Testable { return &taskQueueTestable{t.ns, t} }
+func (t *TaskQueue) GetTestable() tq.Testable { return &taskQueueTestable{t.ns, t} }
 
 /////////////////////////////// taskqueueTxnImpl ///////////////////////////////
 
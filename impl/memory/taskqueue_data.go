@@ -342,7 +342,7 @@ type taskQueueData struct {
 
 var _ memContextObj = (*taskQueueData)(nil)
 
-func newTaskQueueData() memContextObj {
+func newTaskQueueData() *taskQueueData {
 	return &taskQueueData{
 		queues:      map[string]*sortedQueue{"default": newSortedQueue("default", false)},
 		constraints: prodConstraints.TQ(),
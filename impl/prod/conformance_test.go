@@ -0,0 +1,56 @@
+// Copyright 2017 The LUCI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build appengine
+
+package prod
+
+import (
+	"fmt"
+	"testing"
+
+	"go.chromium.org/gae/impl/conformance"
+	"go.chromium.org/gae/service/info"
+
+	"golang.org/x/net/context"
+	"google.golang.org/appengine/aetest"
+)
+
+func TestConformance(t *testing.T) {
+	t.Parallel()
+
+	inst, err := aetest.NewInstance(&aetest.Options{
+		StronglyConsistentDatastore: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize aetest: %v", err)
+	}
+	defer inst.Close()
+
+	// All test cases share one dev_appserver, so give each of them a fresh
+	// namespace to isolate their state.
+	count := 0
+	factory := func() context.Context {
+		req, err := inst.NewRequest("GET", "/", nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		count++
+		return info.MustNamespace(Use(context.Background(), req), fmt.Sprintf("conformance%d", count))
+	}
+
+	conformance.RunDatastoreTests(t, factory)
+	conformance.RunMemcacheTests(t, factory)
+	conformance.RunInfoTests(t, factory)
+}
@@ -36,16 +36,60 @@ func useGI(usrCtx context.Context) context.Context {
 	})
 }
 
+// aeInfoFuncs is the set of AppEngine SDK functions that giImpl delegates to.
+type aeInfoFuncs struct {
+	AccessToken            func(c context.Context, scopes ...string) (string, time.Time, error)
+	AppID                  func(c context.Context) string
+	Datacenter             func(c context.Context) string
+	DefaultVersionHostname func(c context.Context) string
+	InstanceID             func() string
+	IsDevAppServer         func() bool
+	IsOverQuota            func(err error) bool
+	IsTimeoutError         func(err error) bool
+	ModuleHostname         func(c context.Context, module, version, instance string) (string, error)
+	ModuleName             func(c context.Context) string
+	Namespace              func(c context.Context, namespace string) (context.Context, error)
+	PublicCertificates     func(c context.Context) ([]appengine.Certificate, error)
+	RequestID              func(c context.Context) string
+	ServerSoftware         func() string
+	ServiceAccount         func(c context.Context) (string, error)
+	SignBytes              func(c context.Context, bytes []byte) (string, []byte, error)
+	VersionID              func(c context.Context) string
+}
+
+// aeInfo is the AppEngine SDK implementation of aeInfoFuncs. It is a variable
+// so that tests can verify that each giImpl method calls the right SDK
+// function.
+var aeInfo = aeInfoFuncs{
+	AccessToken:            appengine.AccessToken,
+	AppID:                  appengine.AppID,
+	Datacenter:             appengine.Datacenter,
+	DefaultVersionHostname: appengine.DefaultVersionHostname,
+	InstanceID:             appengine.InstanceID,
+	IsDevAppServer:         appengine.IsDevAppServer,
+	IsOverQuota:            appengine.IsOverQuota,
+	IsTimeoutError:         appengine.IsTimeoutError,
+	ModuleHostname:         appengine.ModuleHostname,
+	ModuleName:             appengine.ModuleName,
+	Namespace:              appengine.Namespace,
+	PublicCertificates:     appengine.PublicCertificates,
+	RequestID:              appengine.RequestID,
+	ServerSoftware:         appengine.ServerSoftware,
+	ServiceAccount:         appengine.ServiceAccount,
+	SignBytes:              appengine.SignBytes,
+	VersionID:              appengine.VersionID,
+}
+
 type giImpl struct {
 	usrCtx context.Context
 	aeCtx  context.Context
 }
 
 func (g giImpl) AccessToken(scopes ...string) (token string, expiry time.Time, err error) {
-	return appengine.AccessToken(g.aeCtx, scopes...)
+	return aeInfo.AccessToken(g.aeCtx, scopes...)
 }
 func (g giImpl) AppID() string {
-	return appengine.AppID(g.aeCtx)
+	return aeInfo.AppID(g.aeCtx)
 }
 func (g giImpl) FullyQualifiedAppID() string {
 	return getProbeCache(g.usrCtx).fqaid
@@ -54,28 +98,28 @@ func (g giImpl) GetNamespace() string {
 	return getProbeCache(g.usrCtx).namespace
 }
 func (g giImpl) Datacenter() string {
-	return appengine.Datacenter(g.aeCtx)
+	return aeInfo.Datacenter(g.aeCtx)
 }
 func (g giImpl) DefaultVersionHostname() string {
-	return appengine.DefaultVersionHostname(g.aeCtx)
+	return aeInfo.DefaultVersionHostname(g.aeCtx)
 }
 func (g giImpl) InstanceID() string {
-	return appengine.InstanceID()
+	return aeInfo.InstanceID()
 }
 func (g giImpl) IsDevAppServer() bool {
-	return appengine.IsDevAppServer()
+	return aeInfo.IsDevAppServer()
 }
 func (g giImpl) IsOverQuota(err error) bool {
-	return appengine.IsOverQuota(err)
+	return aeInfo.IsOverQuota(err)
 }
 func (g giImpl) IsTimeoutError(err error) bool {
-	return appengine.IsTimeoutError(err)
+	return aeInfo.IsTimeoutError(err)
 }
 func (g giImpl) ModuleHostname(module, version, instance string) (string, error) {
-	return appengine.ModuleHostname(g.aeCtx, module, version, instance)
+	return aeInfo.ModuleHostname(g.aeCtx, module, version, instance)
 }
 func (g giImpl) ModuleName() (name string) {
-	return appengine.ModuleName(g.aeCtx)
+	return aeInfo.ModuleName(g.aeCtx)
 }
 func (g giImpl) Namespace(namespace string) (context.Context, error) {
 	c := g.usrCtx
@@ -92,13 +136,13 @@ func (g giImpl) Namespace(namespace string) (context.Context, error) {
 	ps := getProdState(c)
 
 	// Apply to current GAE Context.
-	if ps.ctx, err = appengine.Namespace(ps.ctx, namespace); err != nil {
+	if ps.ctx, err = aeInfo.Namespace(ps.ctx, namespace); err != nil {
 		return c, err
 	}
 
 	// Apply to non-transactional Context. Since the previous one applied
 	// successfully, this must succeed.
-	ps.noTxnCtx, err = appengine.Namespace(ps.noTxnCtx, namespace)
+	ps.noTxnCtx, err = aeInfo.Namespace(ps.noTxnCtx, namespace)
 	if err != nil {
 		panic(err)
 	}
@@ -109,7 +153,7 @@ func (g giImpl) Namespace(namespace string) (context.Context, error) {
 	return c, nil
 }
 func (g giImpl) PublicCertificates() ([]info.Certificate, error) {
-	certs, err := appengine.PublicCertificates(g.aeCtx)
+	certs, err := aeInfo.PublicCertificates(g.aeCtx)
 	if err != nil {
 		return nil, err
 	}
@@ -120,24 +164,24 @@ func (g giImpl) PublicCertificates() ([]info.Certificate, error) {
 	return ret, nil
 }
 func (g giImpl) RequestID() string {
-	return appengine.RequestID(g.aeCtx)
+	return aeInfo.RequestID(g.aeCtx)
 }
 func (g giImpl) ServerSoftware() string {
-	return appengine.ServerSoftware()
+	return aeInfo.ServerSoftware()
 }
 func (g giImpl) ServiceAccount() (string, error) {
-	if appengine.IsDevAppServer() {
+	if aeInfo.IsDevAppServer() {
 		// On devserver ServiceAccount returns empty string, but AccessToken works.
 		// We use it to grab developer's email.
 		return developerAccount(g.aeCtx)
 	}
-	return appengine.ServiceAccount(g.aeCtx)
+	return aeInfo.ServiceAccount(g.aeCtx)
 }
 func (g giImpl) SignBytes(bytes []byte) (keyName string, signature []byte, err error) {
-	return appengine.SignBytes(g.aeCtx, bytes)
+	return aeInfo.SignBytes(g.aeCtx, bytes)
 }
 func (g giImpl) VersionID() string {
-	return appengine.VersionID(g.aeCtx)
+	return aeInfo.VersionID(g.aeCtx)
 }
 
 func (g giImpl) GetTestable() info.Testable { return nil }
@@ -0,0 +1,263 @@
+// Copyright 2017 The LUCI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prod
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.chromium.org/gae/service/info"
+
+	"golang.org/x/net/context"
+	"google.golang.org/appengine"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type aeCtxKey string
+
+// fakeAEInfo returns an aeInfoFuncs whose functions record their name (and
+// the "id" value of the Context they were called with, if any) into calls, and
+// return canned values along with err.
+func fakeAEInfo(calls *[]string, err error) aeInfoFuncs {
+	rec := func(name string, c context.Context) {
+		if c != nil {
+			name += "@" + c.Value(aeCtxKey("id")).(string)
+		}
+		*calls = append(*calls, name)
+	}
+	expiry := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	return aeInfoFuncs{
+		AccessToken: func(c context.Context, scopes ...string) (string, time.Time, error) {
+			rec("AccessToken", c)
+			return "token", expiry, err
+		},
+		AppID: func(c context.Context) string {
+			rec("AppID", c)
+			return "app"
+		},
+		Datacenter: func(c context.Context) string {
+			rec("Datacenter", c)
+			return "dc"
+		},
+		DefaultVersionHostname: func(c context.Context) string {
+			rec("DefaultVersionHostname", c)
+			return "app.example.com"
+		},
+		InstanceID: func() string {
+			rec("InstanceID", nil)
+			return "instance"
+		},
+		IsDevAppServer: func() bool {
+			rec("IsDevAppServer", nil)
+			return false
+		},
+		IsOverQuota: func(e error) bool {
+			rec("IsOverQuota", nil)
+			return e == err
+		},
+		IsTimeoutError: func(e error) bool {
+			rec("IsTimeoutError", nil)
+			return e == err
+		},
+		ModuleHostname: func(c context.Context, module, version, instance string) (string, error) {
+			rec("ModuleHostname", c)
+			return module + "." + version + "." + instance, err
+		},
+		ModuleName: func(c context.Context) string {
+			rec("ModuleName", c)
+			return "module"
+		},
+		Namespace: func(c context.Context, namespace string) (context.Context, error) {
+			rec("Namespace", c)
+			if err != nil {
+				return nil, err
+			}
+			return context.WithValue(c, aeCtxKey("ns"), namespace), nil
+		},
+		PublicCertificates: func(c context.Context) ([]appengine.Certificate, error) {
+			rec("PublicCertificates", c)
+			if err != nil {
+				return nil, err
+			}
+			return []appengine.Certificate{{KeyName: "key", Data: []byte("data")}}, nil
+		},
+		RequestID: func(c context.Context) string {
+			rec("RequestID", c)
+			return "request"
+		},
+		ServerSoftware: func() string {
+			rec("ServerSoftware", nil)
+			return "software"
+		},
+		ServiceAccount: func(c context.Context) (string, error) {
+			rec("ServiceAccount", c)
+			return "account@example.com", err
+		},
+		SignBytes: func(c context.Context, bytes []byte) (string, []byte, error) {
+			rec("SignBytes", c)
+			return "key", append([]byte("signed:"), bytes...), err
+		},
+		VersionID: func(c context.Context) string {
+			rec("VersionID", c)
+			return "1.2"
+		},
+	}
+}
+
+func TestGlobalInfo(t *testing.T) {
+	// Not parallel, since this swaps out the package-level aeInfo.
+	defer func(orig aeInfoFuncs) { aeInfo = orig }(aeInfo)
+
+	Convey("giImpl delegates to the AppEngine SDK", t, func() {
+		aeCtx := context.WithValue(context.Background(), aeCtxKey("id"), "ae")
+		c := withProbeCache(context.Background(), &infoProbeCache{fqaid: "s~app"})
+		c = withProdState(c, prodState{ctx: aeCtx, noTxnCtx: aeCtx})
+		g := giImpl{c, aeCtx}
+
+		sdkErr := errors.New("sdk error")
+		expiry := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		for _, err := range []error{nil, sdkErr} {
+			err := err
+			var calls []string
+			aeInfo = fakeAEInfo(&calls, err)
+
+			for _, tc := range []struct {
+				name   string
+				call   func() []interface{}
+				expect []interface{}
+				sdk    string
+			}{
+				{"AccessToken", func() []interface{} {
+					tok, exp, err := g.AccessToken("scope")
+					return []interface{}{tok, exp, err}
+				}, []interface{}{"token", expiry, err}, "AccessToken@ae"},
+				{"AppID", func() []interface{} {
+					return []interface{}{g.AppID()}
+				}, []interface{}{"app"}, "AppID@ae"},
+				{"Datacenter", func() []interface{} {
+					return []interface{}{g.Datacenter()}
+				}, []interface{}{"dc"}, "Datacenter@ae"},
+				{"DefaultVersionHostname", func() []interface{} {
+					return []interface{}{g.DefaultVersionHostname()}
+				}, []interface{}{"app.example.com"}, "DefaultVersionHostname@ae"},
+				{"InstanceID", func() []interface{} {
+					return []interface{}{g.InstanceID()}
+				}, []interface{}{"instance"}, "InstanceID"},
+				{"IsDevAppServer", func() []interface{} {
+					return []interface{}{g.IsDevAppServer()}
+				}, []interface{}{false}, "IsDevAppServer"},
+				{"IsOverQuota", func() []interface{} {
+					return []interface{}{g.IsOverQuota(sdkErr)}
+				}, []interface{}{err == sdkErr}, "IsOverQuota"},
+				{"IsTimeoutError", func() []interface{} {
+					return []interface{}{g.IsTimeoutError(sdkErr)}
+				}, []interface{}{err == sdkErr}, "IsTimeoutError"},
+				{"ModuleHostname", func() []interface{} {
+					host, err := g.ModuleHostname("m", "v", "i")
+					return []interface{}{host, err}
+				}, []interface{}{"m.v.i", err}, "ModuleHostname@ae"},
+				{"ModuleName", func() []interface{} {
+					return []interface{}{g.ModuleName()}
+				}, []interface{}{"module"}, "ModuleName@ae"},
+				{"RequestID", func() []interface{} {
+					return []interface{}{g.RequestID()}
+				}, []interface{}{"request"}, "RequestID@ae"},
+				{"ServerSoftware", func() []interface{} {
+					return []interface{}{g.ServerSoftware()}
+				}, []interface{}{"software"}, "ServerSoftware"},
+				{"ServiceAccount", func() []interface{} {
+					acct, err := g.ServiceAccount()
+					return []interface{}{acct, err}
+				}, []interface{}{"account@example.com", err}, "IsDevAppServer,ServiceAccount@ae"},
+				{"SignBytes", func() []interface{} {
+					key, sig, err := g.SignBytes([]byte("hi"))
+					return []interface{}{key, sig, err}
+				}, []interface{}{"key", []byte("signed:hi"), err}, "SignBytes@ae"},
+				{"VersionID", func() []interface{} {
+					return []interface{}{g.VersionID()}
+				}, []interface{}{"1.2"}, "VersionID@ae"},
+			} {
+				tc := tc
+				Convey(tc.name+" (err="+errString(err)+")", func() {
+					calls = nil
+					So(tc.call(), ShouldResemble, tc.expect)
+					So(strings.Join(calls, ","), ShouldEqual, tc.sdk)
+				})
+			}
+		}
+
+		Convey("PublicCertificates", func() {
+			var calls []string
+
+			aeInfo = fakeAEInfo(&calls, nil)
+			certs, err := g.PublicCertificates()
+			So(err, ShouldBeNil)
+			So(certs, ShouldResemble, []info.Certificate{{KeyName: "key", Data: []byte("data")}})
+
+			aeInfo = fakeAEInfo(&calls, sdkErr)
+			_, err = g.PublicCertificates()
+			So(err, ShouldEqual, sdkErr)
+
+			So(calls, ShouldResemble, []string{"PublicCertificates@ae", "PublicCertificates@ae"})
+		})
+
+		Convey("Namespace", func() {
+			var calls []string
+
+			Convey("derives a new Context", func() {
+				aeInfo = fakeAEInfo(&calls, nil)
+				nc, err := g.Namespace("ns")
+				So(err, ShouldBeNil)
+				So(calls, ShouldResemble, []string{"Namespace@ae", "Namespace@ae"})
+
+				So(getProbeCache(nc).namespace, ShouldEqual, "ns")
+				So(getProbeCache(nc).fqaid, ShouldEqual, "s~app")
+				So(getProdState(nc).ctx.Value(aeCtxKey("ns")), ShouldEqual, "ns")
+				So(getProdState(nc).noTxnCtx.Value(aeCtxKey("ns")), ShouldEqual, "ns")
+
+				// The original Context is untouched.
+				So(getProbeCache(c).namespace, ShouldEqual, "")
+				So(getProdState(c).ctx.Value(aeCtxKey("ns")), ShouldBeNil)
+
+				Convey("and is a no-op for the current namespace", func() {
+					calls = nil
+					same, err := giImpl{nc, aeCtx}.Namespace("ns")
+					So(err, ShouldBeNil)
+					So(same, ShouldEqual, nc)
+					So(calls, ShouldBeNil)
+				})
+			})
+
+			Convey("propagates errors", func() {
+				aeInfo = fakeAEInfo(&calls, sdkErr)
+				nc, err := g.Namespace("ns")
+				So(err, ShouldEqual, sdkErr)
+				So(nc, ShouldEqual, c)
+			})
+		})
+	})
+}
+
+func errString(err error) string {
+	if err == nil {
+		return "nil"
+	}
+	return err.Error()
+}
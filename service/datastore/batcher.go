@@ -158,6 +158,111 @@ func CountBatch(c context.Context, batchSize int32, q *Query) (int64, error) {
 	return Count(withQueryBatching(c, batchSize), q)
 }
 
+// defaultDeleteAllBatchSize is the batch size used by DeleteAll if neither the
+// caller nor the implementation's Constraints specify one.
+const defaultDeleteAllBatchSize = 500
+
+// DeleteAll deletes all of the entities matching q, returning the number of
+// entities which were deleted.
+//
+// q is run as a keys-only query in batches of at most batchSize keys (see
+// RunBatch), and each batch is deleted with a single DeleteMulti call before
+// the next batch is queried. If batchSize is <= 0, or exceeds the
+// implementation's MaxDeleteSize constraint, the constraint (or a reasonable
+// default) is used instead. Batches are chained together using query cursors,
+// so entities which are added to the matching set during DeleteAll will only
+// be deleted if they sort after the current batch.
+//
+// Projection (and distinct) queries are rejected, since they can return the
+// same key multiple times. A query limit caps the total number of keys
+// examined.
+//
+// If some entities can't be deleted, DeleteAll continues on to the remaining
+// batches and returns a *DeleteAllError describing the failed keys. If a query
+// or DeleteMulti call fails outright, or the Context is cancelled or reaches
+// its deadline, DeleteAll stops and returns a *DeleteAllError which can be
+// used to resume. ErrNoSuchEntity is not considered a failure, but such
+// entities are not counted as deleted.
+func DeleteAll(c context.Context, q *Query, batchSize int) (deleted int, err error) {
+	fq, err := q.Finalize()
+	if err != nil {
+		return 0, err
+	}
+	if len(fq.Project()) > 0 {
+		return 0, errors.New("datastore: DeleteAll does not support projection queries")
+	}
+
+	if max := Raw(c).Constraints().MaxDeleteSize; max > 0 && (batchSize <= 0 || batchSize > max) {
+		batchSize = max
+	}
+	if batchSize <= 0 {
+		batchSize = defaultDeleteAllBatchSize
+	}
+
+	dae := &DeleteAllError{Limit: -1}
+	dae.Cursor, _ = fq.Bounds()
+	dae.Offset, _ = fq.Offset()
+	if limit, ok := fq.Limit(); ok {
+		dae.Limit = limit
+	}
+
+	if fq, err = q.KeysOnly(true).Finalize(); err != nil {
+		panic(fmt.Errorf("failed to finalize internal query: %v", err))
+	}
+
+	raw := Raw(withQueryBatching(c, int32(batchSize)))
+	keys := make([]*Key, 0, batchSize)
+	deleteKeys := func() error {
+		return filterStop(raw.DeleteMulti(keys, func(idx int, err error) error {
+			switch err {
+			case nil:
+				deleted++
+			case ErrNoSuchEntity:
+			default:
+				dae.Keys = append(dae.Keys, keys[idx])
+				dae.Errors = append(dae.Errors, err)
+			}
+			return nil
+		}))
+	}
+
+	err = filterStop(raw.Run(fq, func(k *Key, _ PropertyMap, getCursor CursorCB) error {
+		keys = append(keys, k)
+		if len(keys) < batchSize {
+			return nil
+		}
+
+		// This key completes a batch, so getCursor is free.
+		next, err := getCursor()
+		if err != nil {
+			return fmt.Errorf("failed to get cursor: %v", err)
+		}
+		if err := deleteKeys(); err != nil {
+			return err
+		}
+
+		// The offset (if any) was consumed by the first batch.
+		dae.Cursor, dae.Offset = next, 0
+		if dae.Limit >= 0 {
+			dae.Limit -= int32(len(keys))
+		}
+		keys = keys[:0]
+		return nil
+	}))
+	if err == nil && len(keys) > 0 {
+		err = deleteKeys()
+	}
+	if err != nil {
+		dae.Err = err
+		return deleted, dae
+	}
+
+	if len(dae.Keys) > 0 {
+		return deleted, dae
+	}
+	return deleted, nil
+}
+
 func withQueryBatching(c context.Context, batchSize int32) context.Context {
 	if batchSize <= 0 {
 		return c
@@ -202,7 +307,8 @@ func (f *queryBatchingFilter) Run(fq *FinalizedQuery, cb RawRunCB) error {
 
 		iterQuery := fq.Original()
 		if nextCursor != nil {
-			iterQuery = iterQuery.Start(nextCursor)
+			// The offset (if any) was consumed by the first batch.
+			iterQuery = iterQuery.Start(nextCursor).Offset(-1)
 			nextCursor = nil
 		}
 		iterLimit := f.batchSize
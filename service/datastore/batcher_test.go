@@ -21,10 +21,12 @@ import (
 	"testing"
 
 	"go.chromium.org/gae/service/info"
+	"go.chromium.org/luci/common/errors"
 
 	"golang.org/x/net/context"
 
 	. "github.com/smartystreets/goconvey/convey"
+	. "go.chromium.org/luci/common/testing/assertions"
 )
 
 type counterFilter struct {
@@ -294,3 +296,174 @@ func TestBatchFilter(t *testing.T) {
 		}
 	})
 }
+
+func TestDeleteAll(t *testing.T) {
+	t.Parallel()
+
+	Convey("A testing environment", t, func() {
+		c := info.Set(context.Background(), fakeInfo{})
+		fds := fakeDatastore{entities: 10}
+		c = SetRawFactory(c, fds.factory())
+
+		cf := counterFilter{}
+		c = AddRawFilters(c, cf.filter())
+
+		var deleted []int64
+		fds.onDelete = func(k *Key) { deleted = append(deleted, k.IntID()) }
+
+		Convey("deletes everything in batches", func() {
+			n, err := DeleteAll(c, NewQuery("Kind"), 3)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 10)
+			So(deleted, ShouldResemble, []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+			So(cf.delete, ShouldEqual, 4)
+			So(cf.run, ShouldEqual, 4)
+		})
+
+		Convey("an exact multiple of the batch size needs one extra query", func() {
+			n, err := DeleteAll(c, NewQuery("Kind"), 5)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 10)
+			So(cf.delete, ShouldEqual, 2)
+			So(cf.run, ShouldEqual, 3)
+		})
+
+		Convey("respects the MaxDeleteSize constraint", func() {
+			fds.constraints.MaxDeleteSize = 4
+			n, err := DeleteAll(c, NewQuery("Kind"), 0)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 10)
+			So(cf.delete, ShouldEqual, 3)
+
+			cf.delete = 0
+			_, err = DeleteAll(c, NewQuery("Kind"), 100)
+			So(err, ShouldBeNil)
+			So(cf.delete, ShouldEqual, 3)
+		})
+
+		Convey("respects the query limit", func() {
+			n, err := DeleteAll(c, NewQuery("Kind").Limit(7), 3)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 7)
+			So(deleted, ShouldResemble, []int64{1, 2, 3, 4, 5, 6, 7})
+		})
+
+		Convey("refuses projection queries", func() {
+			_, err := DeleteAll(c, NewQuery("Kind").Project("Value"), 3)
+			So(err, ShouldErrLike, "does not support projection")
+			So(cf.run, ShouldEqual, 0)
+		})
+
+		Convey("aggregates individual failures", func() {
+			fds.keyForResult = func(i int32, kctx KeyContext) *Key {
+				if i%4 == 1 {
+					return kctx.MakeKey("Fail", i+1)
+				}
+				return kctx.MakeKey("Kind", i+1)
+			}
+			n, err := DeleteAll(c, NewQuery("Kind"), 3)
+			So(n, ShouldEqual, 7)
+
+			dae, ok := err.(*DeleteAllError)
+			So(ok, ShouldBeTrue)
+			So(dae.Err, ShouldBeNil)
+			So(dae.Keys, ShouldResemble, []*Key{
+				MakeKey(c, "Fail", 2), MakeKey(c, "Fail", 6), MakeKey(c, "Fail", 10)})
+			So(dae.Errors, ShouldResemble, errors.MultiError{errFail, errFail, errFail})
+			So(err, ShouldErrLike, "failed to delete 3 entities")
+		})
+
+		Convey("stops on a batch failure, and can resume", func() {
+			fds.keyForResult = func(i int32, kctx KeyContext) *Key {
+				if i == 6 {
+					return kctx.MakeKey("FailAll", i+1)
+				}
+				return kctx.MakeKey("Kind", i+1)
+			}
+			n, err := DeleteAll(c, NewQuery("Kind"), 3)
+			So(n, ShouldEqual, 6)
+			So(err, ShouldErrLike, "DeleteAll halted")
+
+			dae := err.(*DeleteAllError)
+			So(dae.Err, ShouldEqual, errFailAll)
+			So(dae.Cursor, ShouldEqual, fakeCursor(6))
+
+			fds.keyForResult = nil
+			c = SetRawFactory(c, fds.factory())
+			n, err = DeleteAll(c, dae.Resume(NewQuery("Kind")), 3)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 4)
+			So(deleted[len(deleted)-4:], ShouldResemble, []int64{7, 8, 9, 10})
+		})
+
+		Convey("resumes with the remaining offset and limit", func() {
+			fail := int32(1)
+			fds.keyForResult = func(i int32, kctx KeyContext) *Key {
+				if i == fail {
+					return kctx.MakeKey("FailAll", i+1)
+				}
+				return kctx.MakeKey("Kind", i+1)
+			}
+			c = SetRawFactory(c, fds.factory())
+			q := NewQuery("Kind").Offset(1).Limit(7)
+
+			Convey("when the first batch fails", func() {
+				n, err := DeleteAll(c, q, 3)
+				So(n, ShouldEqual, 0)
+
+				dae := err.(*DeleteAllError)
+				So(dae.Err, ShouldEqual, errFailAll)
+				So(dae.Cursor, ShouldBeNil)
+				So(dae.Offset, ShouldEqual, 1)
+				So(dae.Limit, ShouldEqual, 7)
+
+				fds.keyForResult = nil
+				c = SetRawFactory(c, fds.factory())
+				n, err = DeleteAll(c, dae.Resume(q), 3)
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 7)
+				So(deleted, ShouldResemble, []int64{2, 3, 4, 5, 6, 7, 8})
+			})
+
+			Convey("when a later batch fails", func() {
+				fail = 4
+				c = SetRawFactory(c, fds.factory())
+				n, err := DeleteAll(c, q, 3)
+				So(n, ShouldEqual, 3)
+
+				dae := err.(*DeleteAllError)
+				So(dae.Err, ShouldEqual, errFailAll)
+				So(dae.Cursor, ShouldEqual, fakeCursor(4))
+				So(dae.Offset, ShouldEqual, 0)
+				So(dae.Limit, ShouldEqual, 4)
+
+				fds.keyForResult = nil
+				c = SetRawFactory(c, fds.factory())
+				n, err = DeleteAll(c, dae.Resume(q), 3)
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 4)
+				So(deleted, ShouldResemble, []int64{2, 3, 4, 5, 6, 7, 8})
+			})
+		})
+
+		Convey("stops when the Context is cancelled", func() {
+			c, cancel := context.WithCancel(c)
+			defer cancel()
+			fds.onDelete = func(k *Key) {
+				deleted = append(deleted, k.IntID())
+				if len(deleted) == 3 {
+					cancel()
+				}
+			}
+			c = SetRawFactory(c, fds.factory())
+
+			n, err := DeleteAll(c, NewQuery("Kind"), 3)
+			So(n, ShouldEqual, 3)
+			So(deleted, ShouldResemble, []int64{1, 2, 3})
+
+			dae := err.(*DeleteAllError)
+			So(dae.Err, ShouldEqual, context.Canceled)
+			So(dae.Cursor, ShouldEqual, fakeCursor(3))
+		})
+	})
+}
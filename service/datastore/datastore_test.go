@@ -79,6 +79,9 @@ func (f *fakeDatastore) Run(fq *FinalizedQuery, cb RawRunCB) error {
 	if start != nil {
 		cur = int32(start.(fakeCursor))
 	}
+	if off, ok := fq.Offset(); ok {
+		cur += off
+	}
 
 	remaining := int32(f.entities - cur)
 	if end != nil {
@@ -438,6 +441,13 @@ func TestKeyForObj(t *testing.T) {
 				So(KeyForObj(c, pm).String(), ShouldEqual, `s~aid:ns:/Hello,"world"/Sup,100`)
 			})
 
+			Convey("a propmap with $key and conflicting $id, $kind", func() {
+				pm := PropertyMap{"$kind": MkPropertyNI("Sup"), "$id": MkPropertyNI(100)}
+				So(pm.SetMeta("key", k), ShouldBeTrue)
+				So(KeyForObj(c, pm).String(), ShouldEqual, `s~aid:ns:/Hello,"world"`)
+				So(GetMetaDefault(pm, "kind", ""), ShouldEqual, "Hello")
+			})
+
 			Convey("a pls with $id, $parent", func() {
 				pls := GetPLS(&CommonStruct{ID: 1})
 				So(KeyForObj(c, pls).String(), ShouldEqual, `s~aid:ns:/CommonStruct,1`)
@@ -664,6 +674,24 @@ func TestPut(t *testing.T) {
 					So(IsErrInvalidKey(Put(c, bp)), ShouldBeTrue)
 				})
 
+				Convey("batch rejected for an invalid key", func() {
+					type BadParent struct {
+						ID     int64 `gae:"$id"`
+						Parent *Key  `gae:"$parent"`
+					}
+					cs := &CommonStruct{Value: 1}
+					bp := &BadParent{ID: 1, Parent: MakeKey(c, "Something", 0)}
+
+					err := Put(c, cs, bp)
+					So(err, ShouldHaveSameTypeAs, errors.MultiError{})
+					me := err.(errors.MultiError)
+					So(me[0], ShouldBeNil)
+					So(IsErrInvalidKey(me[1]), ShouldBeTrue)
+
+					// cs was never written, so it didn't get an ID.
+					So(cs.ID, ShouldEqual, 0)
+				})
+
 				Convey("vararg with errors", func() {
 					successSlice := []CommonStruct{{Value: 0}, {Value: 1}}
 					failSlice := []FakePLS{{Kind: "Fail"}, {Value: 3}}
@@ -1064,6 +1092,13 @@ func TestGet(t *testing.T) {
 					So(cs.Value, ShouldEqual, 1)
 				})
 
+				Convey("PropertyMap", func() {
+					pm := PropertyMap{"$key": MkPropertyNI(MakeKey(c, "Index", 7))}
+					So(Get(c, pm), ShouldBeNil)
+					So(pm.Slice("Value"), ShouldResemble, PropertySlice{MkProperty(7)})
+					So(GetMetaDefault(pm, "id", 0), ShouldEqual, 7)
+				})
+
 				Convey("Raw access too", func() {
 					rds := Raw(c)
 					keys := []*Key{MakeKey(c, "Kind", 1)}
@@ -76,3 +76,61 @@ func (e *ErrFieldMismatch) Error() string {
 	return fmt.Sprintf("gae: cannot load field %q into a %q: %s",
 		e.FieldName, e.StructType, e.Reason)
 }
+
+// ErrMissingRequiredFields is returned (inside of a MultiError) when loading
+// into a struct which has fields tagged as `required`, and one or more of
+// those fields didn't receive a non-null value.
+//
+// It's intentionally distinct from ErrFieldMismatch, so that code which
+// ignores field mismatches will still observe missing required fields.
+//
+// FieldNames contains the flattened names of the missing properties. Fields
+// inside of a slice of structs are reported per element, e.g. "Items[1].Owner".
+type ErrMissingRequiredFields struct {
+	StructType reflect.Type
+	FieldNames []string
+}
+
+func (e *ErrMissingRequiredFields) Error() string {
+	return fmt.Sprintf("gae: cannot load %q: missing required fields %q",
+		e.StructType, e.FieldNames)
+}
+
+// DeleteAllError is returned by DeleteAll when it fails to delete some of the
+// entities matching its query.
+type DeleteAllError struct {
+	// Keys are the keys of the entities which could not be deleted. Errors is
+	// aligned with Keys, and holds the error for each of them.
+	Keys   []*Key
+	Errors errors.MultiError
+
+	// Err, if not nil, is the query or DeleteMulti error which halted DeleteAll.
+	Err error
+
+	// Cursor is the query position following the last batch which DeleteAll
+	// fully processed, or the query's original start cursor if no batch was
+	// processed.
+	//
+	// Offset and Limit are what remains of the query's offset and limit at
+	// Cursor. Offset is 0 once a batch has been processed, and Limit is -1 if
+	// the query had no limit.
+	//
+	// Running DeleteAll again with the query's Start, Offset and Limit set to
+	// these resumes the deletion. See Resume.
+	Cursor Cursor
+	Offset int32
+	Limit  int32
+}
+
+// Resume returns a copy of q, which should be the query originally passed to
+// DeleteAll, which resumes the deletion from where it halted.
+func (e *DeleteAllError) Resume(q *Query) *Query {
+	return q.Start(e.Cursor).Offset(e.Offset).Limit(e.Limit)
+}
+
+func (e *DeleteAllError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("gae: DeleteAll halted: %s", e.Err)
+	}
+	return fmt.Sprintf("gae: DeleteAll failed to delete %d entities: %s", len(e.Keys), e.Errors)
+}
@@ -323,6 +323,103 @@ func RunInTransaction(c context.Context, f func(c context.Context) error, opts *
 	return Raw(c).RunInTransaction(f, opts)
 }
 
+// GetOrInsert atomically loads obj from the datastore, creating it if it
+// doesn't exist yet. It returns true iff obj was created by this call.
+//
+// obj must be a single object of any type accepted by Get and Put (e.g. a
+// pointer-to-struct, or a PropertyLoadSaver) whose key is complete. An
+// incomplete key is an error, since there's nothing to look up.
+//
+// Inside of a transaction keyed on obj, GetOrInsert Gets obj. If it exists, obj
+// is populated from the datastore and init is not called. Otherwise init (if not
+// nil) is called with obj to fill it in, and obj is Put. If init returns an
+// error, the transaction is aborted and that error is returned.
+//
+// If the transaction is retried (e.g. because of contention), obj is first
+// restored to the state it was in when GetOrInsert was called, so values
+// written by a failed attempt's init don't leak into the result. This restore
+// is shallow: it resets obj's fields, but not the contents of the slices, maps
+// or pointers which they refer to. init should therefore replace fields (e.g.
+// obj.Tags = []string{"a"}) rather than mutate their values in place (e.g.
+// obj.Tags[0] = "a").
+//
+// If c is already in a transaction, GetOrInsert participates in it instead of
+// starting a new one.
+func GetOrInsert(c context.Context, obj interface{}, init func(obj interface{}) error) (created bool, err error) {
+	key, err := KeyForObjErr(c, obj)
+	if err != nil {
+		return false, err
+	}
+	if key.IsIncomplete() {
+		return false, MakeErrInvalidKey("GetOrInsert requires a complete key, got %s", key).Err()
+	}
+
+	restore := snapshotObj(obj)
+	attempt := func(c context.Context) error {
+		restore()
+		created = false
+
+		switch err := Get(c, obj); err {
+		case nil:
+			return nil
+		case ErrNoSuchEntity:
+		default:
+			return err
+		}
+
+		if init != nil {
+			if err := init(obj); err != nil {
+				return err
+			}
+		}
+		if err := Put(c, obj); err != nil {
+			return err
+		}
+		created = true
+		return nil
+	}
+
+	if CurrentTransaction(c) != nil {
+		err = attempt(c)
+	} else {
+		err = RunInTransaction(c, attempt, nil)
+	}
+	if err != nil {
+		created = false
+	}
+	return
+}
+
+// snapshotObj returns a function which restores obj to the state it was in
+// when snapshotObj was called.
+//
+// Pointers have their pointed-to values restored, and PropertyMaps have their
+// entries restored. The restore is shallow. Other types are left untouched.
+func snapshotObj(obj interface{}) func() {
+	if pm, ok := obj.(PropertyMap); ok {
+		orig := make(PropertyMap, len(pm))
+		for k, v := range pm {
+			orig[k] = v
+		}
+		return func() {
+			for k := range pm {
+				delete(pm, k)
+			}
+			for k, v := range orig {
+				pm[k] = v
+			}
+		}
+	}
+
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return func() {}
+	}
+	orig := reflect.New(v.Elem().Type()).Elem()
+	orig.Set(v.Elem())
+	return func() { v.Elem().Set(orig) }
+}
+
 // Run executes the given query, and calls `cb` for each successfully
 // retrieved item.
 //
@@ -649,7 +746,8 @@ func putRaw(raw RawInterface, kctx KeyContext, src []interface{}) error {
 			return nil
 		}
 
-		if !key.Equal(keys[idx]) {
+		// key may be nil if the batch was rejected because of another entry.
+		if key != nil && !key.Equal(keys[idx]) {
 			mat, v := mma.get(index)
 			mat.setKey(v, key)
 		}
@@ -54,13 +54,32 @@ func (k KeyTok) ID() Property {
 
 // Less returns true iff k would sort before other.
 func (k KeyTok) Less(other KeyTok) bool {
-	if k.Kind < other.Kind {
-		return true
-	} else if k.Kind > other.Kind {
-		return false
+	return k.compare(other) < 0
+}
+
+// compare compares k to other, returning -1, 0 or 1. Tokens are ordered by
+// Kind, and then by ID, with all integer IDs sorting before all string IDs.
+func (k KeyTok) compare(other KeyTok) int {
+	if cmp := strings.Compare(k.Kind, other.Kind); cmp != 0 {
+		return cmp
 	}
-	a, b := k.ID(), other.ID()
-	return a.Less(&b)
+
+	switch aStr, bStr := k.StringID != "", other.StringID != ""; {
+	case aStr && bStr:
+		return strings.Compare(k.StringID, other.StringID)
+	case aStr:
+		return 1
+	case bStr:
+		return -1
+	}
+
+	switch {
+	case k.IntID < other.IntID:
+		return -1
+	case k.IntID > other.IntID:
+		return 1
+	}
+	return 0
 }
 
 // KeyContext is the context in which a key is generated.
@@ -388,32 +407,54 @@ func (k *Key) Root() *Key {
 }
 
 // Less returns true iff k would sort before other.
+//
+// See KeyCompare for the ordering.
 func (k *Key) Less(other *Key) bool {
-	if k.kc.AppID < other.kc.AppID {
-		return true
-	} else if k.kc.AppID > other.kc.AppID {
-		return false
-	}
+	return KeyLess(k, other)
+}
 
-	if k.kc.Namespace < other.kc.Namespace {
-		return true
-	} else if k.kc.Namespace > other.kc.Namespace {
-		return false
+// KeyLess returns true iff a would sort before b.
+//
+// See KeyCompare for the ordering.
+func KeyLess(a, b *Key) bool {
+	return KeyCompare(a, b) < 0
+}
+
+// KeyCompare compares two keys in datastore order, returning -1 if a sorts
+// before b, 1 if a sorts after b and 0 if they are equal.
+//
+// Keys are ordered by AppID, then by Namespace, and then by their tokens,
+// pairwise from the root down. Tokens are ordered by Kind, and then by ID, with
+// all integer IDs sorting before all string IDs. A key sorts immediately before
+// all of its descendants.
+//
+// This order matches the byte order of keys encoded by
+// serialize.WriteKey.
+func KeyCompare(a, b *Key) int {
+	if cmp := strings.Compare(a.kc.AppID, b.kc.AppID); cmp != 0 {
+		return cmp
+	}
+	if cmp := strings.Compare(a.kc.Namespace, b.kc.Namespace); cmp != 0 {
+		return cmp
 	}
 
-	lim := len(k.toks)
-	if len(other.toks) < lim {
-		lim = len(other.toks)
+	lim := len(a.toks)
+	if len(b.toks) < lim {
+		lim = len(b.toks)
 	}
 	for i := 0; i < lim; i++ {
-		a, b := k.toks[i], other.toks[i]
-		if a.Less(b) {
-			return true
-		} else if b.Less(a) {
-			return false
+		if cmp := a.toks[i].compare(b.toks[i]); cmp != 0 {
+			return cmp
 		}
 	}
-	return len(k.toks) < len(other.toks)
+
+	switch {
+	case len(a.toks) < len(b.toks):
+		return -1
+	case len(a.toks) > len(b.toks):
+		return 1
+	}
+	return 0
 }
 
 // HasAncestor returns true iff other is an ancestor of k (or if other == k).
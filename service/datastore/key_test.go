@@ -267,4 +267,31 @@ func TestKeySort(t *testing.T) {
 			So(s[i], shouldNotBeLess, s[i-1])
 		}
 	})
+
+	Convey("KeyCompare works", t, func() {
+		kc := MkKeyContext("a", "n")
+		s := []*Key{
+			kc.MakeKey("kind", 0),
+			kc.MakeKey("kind", 1),
+			kc.MakeKey("kind", 1, "child", 1),
+			kc.MakeKey("kind", 1, "child", "a"),
+			kc.MakeKey("kind", 2),
+			kc.MakeKey("kind", "1"),
+			kc.MakeKey("kind", "1", "child", 1),
+			kc.MakeKey("kind", "10"),
+			kc.MakeKey("kind", "2"),
+			kc.MakeKey("other", 1),
+		}
+
+		for i := range s {
+			So(KeyCompare(s[i], s[i]), ShouldEqual, 0)
+			So(KeyLess(s[i], s[i]), ShouldBeFalse)
+			if i > 0 {
+				So(KeyCompare(s[i-1], s[i]), ShouldEqual, -1)
+				So(KeyCompare(s[i], s[i-1]), ShouldEqual, 1)
+				So(KeyLess(s[i-1], s[i]), ShouldBeTrue)
+				So(KeyLess(s[i], s[i-1]), ShouldBeFalse)
+			}
+		}
+	})
 }
@@ -49,8 +49,8 @@ import (
 //   * A slice of any of the above types
 //
 // GetPLS supports the following struct tag syntax:
-//   `gae:"fieldName[,noindex]"` -- an alternate fieldname for an exportable
-//      field.  When the struct is serialized or deserialized, fieldName will be
+//   `gae:"fieldName[,noindex][,required]"` -- an alternate fieldname for an
+//      exportable field.  When the struct is serialized or deserialized, fieldName will be
 //      associated with the struct field instead of the field's Go name. This is
 //      useful when writing Go code which interfaces with appengine code written
 //      in other languages (like python) which use lowercase as their default
@@ -65,6 +65,16 @@ import (
 //      field's actual name. Note that by default, all fields (with indexable
 //      types) are indexed.
 //
+//      if required is specified, then Load will fail with an
+//      ErrMissingRequiredFields error (returned inside of the MultiError) if
+//      the field doesn't receive at least one non-null value. A property which
+//      is present but null counts as missing, since loading it would just
+//      produce the zero value. Required fields inside of a nested struct are
+//      checked by their flattened name ("Inner.Field"), and required fields
+//      inside of a slice of structs must be present for every element of the
+//      slice. A struct-typed field may not itself be required. Save is not
+//      affected by this option.
+//
 //   `gae:"$metaKey[,<value>]` -- indicates a field is metadata. Metadata
 //      can be used to control filter behavior, or to store key data when using
 //      the Interface.KeyForObj* methods. The supported field types are:
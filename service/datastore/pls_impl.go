@@ -41,12 +41,24 @@ type structTag struct {
 	canSet         bool
 }
 
+// requiredField is a flattened property name which must receive a non-null
+// value when loading into a struct.
+type requiredField struct {
+	name string
+
+	// slicePrefix is non-empty if this field lives inside of a slice of
+	// substructs. It's the flattened name prefix (including the trailing ".") of
+	// that slice field, and every element of the slice must receive a value.
+	slicePrefix string
+}
+
 type structCodec struct {
 	byMeta    map[string]int
 	byName    map[string]int
 	bySpecial map[string]int
 
 	byIndex  []structTag
+	required []requiredField
 	hasSlice bool
 	problem  error
 }
@@ -78,6 +90,13 @@ func (p *structPLS) Load(propMap PropertyMap) error {
 			extra = p.o.Field(i).Addr().Interface().(*PropertyMap)
 		}
 	}
+	// loaded tracks, per property name, which of its values were loaded and
+	// non-null. It's only needed if the struct has required fields.
+	loaded := map[string][]bool(nil)
+	if len(p.c.required) > 0 {
+		loaded = make(map[string][]bool, len(propMap))
+	}
+
 	t := reflect.Type(nil)
 	for name, pdata := range propMap {
 		pslice := pdata.Slice()
@@ -91,6 +110,7 @@ func (p *structPLS) Load(propMap PropertyMap) error {
 						}
 						(*extra)[name] = pslice
 					}
+					delete(loaded, name)
 					break // go to the next property in propMap
 				} else {
 					if t == nil {
@@ -102,10 +122,24 @@ func (p *structPLS) Load(propMap PropertyMap) error {
 						Reason:     reason,
 					})
 				}
+			} else if loaded != nil && prop.Type() != PTNull {
+				if loaded[name] == nil {
+					loaded[name] = make([]bool, len(pslice))
+				}
+				loaded[name][i] = true
 			}
 		}
 	}
 
+	if loaded != nil {
+		if missing := p.c.missingRequired(propMap, loaded); len(missing) > 0 {
+			convFailures = append(convFailures, &ErrMissingRequiredFields{
+				StructType: p.o.Type(),
+				FieldNames: missing,
+			})
+		}
+	}
+
 	if len(convFailures) > 0 {
 		return convFailures
 	}
@@ -113,6 +147,47 @@ func (p *structPLS) Load(propMap PropertyMap) error {
 	return nil
 }
 
+// missingRequired returns the names of the required fields which did not
+// receive a value, according to loaded.
+//
+// Required fields inside of a slice of substructs must be loaded for every
+// element of that slice. The number of elements is the length of the longest
+// property in propMap belonging to the slice.
+func (c *structCodec) missingRequired(propMap PropertyMap, loaded map[string][]bool) (missing []string) {
+	for _, r := range c.required {
+		vals := loaded[r.name]
+		if r.slicePrefix == "" {
+			found := false
+			for _, v := range vals {
+				if v {
+					found = true
+					break
+				}
+			}
+			if !found {
+				missing = append(missing, r.name)
+			}
+			continue
+		}
+
+		elems := 0
+		for name, pdata := range propMap {
+			if strings.HasPrefix(name, r.slicePrefix) {
+				if l := len(pdata.Slice()); l > elems {
+					elems = l
+				}
+			}
+		}
+		for i := 0; i < elems; i++ {
+			if i >= len(vals) || !vals[i] {
+				missing = append(missing, fmt.Sprintf("%s[%d].%s",
+					r.slicePrefix[:len(r.slicePrefix)-1], i, r.name[len(r.slicePrefix):]))
+			}
+		}
+	}
+	return
+}
+
 func loadInner(codec *structCodec, structValue reflect.Value, index int, name string, p Property, requireSlice bool) string {
 	var v reflect.Value
 	// Traverse a struct's struct-typed fields.
@@ -637,6 +712,15 @@ func getStructCodecLocked(t reflect.Type) (c *structCodec) {
 			if name != "" {
 				name += "."
 			}
+			for _, r := range sub.required {
+				r.name = name + r.name
+				if r.slicePrefix != "" {
+					r.slicePrefix = name + r.slicePrefix
+				} else if st.isSlice {
+					r.slicePrefix = name
+				}
+				c.required = append(c.required, r)
+			}
 			for relName := range sub.byName {
 				absName := name + relName
 				if _, ok := c.byName[absName]; ok {
@@ -665,8 +749,17 @@ func getStructCodecLocked(t reflect.Type) (c *structCodec) {
 			c.byName[name] = i
 		}
 		st.name = name
-		if opts == "noindex" {
-			st.idxSetting = NoIndex
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "noindex":
+				st.idxSetting = NoIndex
+			case "required":
+				if st.substructCodec != nil {
+					c.problem = me("struct field %q cannot be required; mark its fields as required instead", f.Name)
+					return
+				}
+				c.required = append(c.required, requiredField{name: name})
+			}
 		}
 	}
 	if c.problem == errRecursiveStruct {
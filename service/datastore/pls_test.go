@@ -29,6 +29,7 @@ import (
 
 	. "github.com/smartystreets/goconvey/convey"
 	"go.chromium.org/gae/service/blobstore"
+	"go.chromium.org/luci/common/errors"
 	. "go.chromium.org/luci/common/testing/assertions"
 )
 
@@ -2051,3 +2052,130 @@ func TestMeta(t *testing.T) {
 		})
 	})
 }
+
+type RequiredInner struct {
+	Owner string `gae:",required"`
+	Note  string
+}
+
+type Required struct {
+	Owner   string    `gae:",required"`
+	Created time.Time `gae:",noindex,required"`
+	Tags    []string  `gae:",required"`
+	Other   int64
+
+	Sub   RequiredInner
+	Items []RequiredInner
+}
+
+func TestRequired(t *testing.T) {
+	t.Parallel()
+
+	Convey("Test required fields", t, func() {
+		now := time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+		pm := PropertyMap{
+			"Owner":       mp("bob"),
+			"Created":     mpNI(now),
+			"Tags":        PropertySlice{mp("a"), mp("b")},
+			"Sub.Owner":   mp("sue"),
+			"Items.Owner": PropertySlice{mp("x"), mp("y")},
+			"Items.Note":  PropertySlice{mp(""), mp("")},
+		}
+
+		missing := func(err error) []string {
+			So(err, ShouldHaveSameTypeAs, errors.MultiError(nil))
+			for _, e := range err.(errors.MultiError) {
+				if me, ok := e.(*ErrMissingRequiredFields); ok {
+					So(me.StructType, ShouldEqual, reflect.TypeOf(Required{}))
+					return me.FieldNames
+				}
+			}
+			return nil
+		}
+
+		Convey("loads when all required fields are present", func() {
+			r := &Required{}
+			So(GetPLS(r).Load(pm), ShouldBeNil)
+			So(r.Owner, ShouldEqual, "bob")
+			So(r.Items, ShouldHaveLength, 2)
+		})
+
+		Convey("reports missing fields", func() {
+			delete(pm, "Owner")
+			delete(pm, "Tags")
+			delete(pm, "Sub.Owner")
+			err := GetPLS(&Required{}).Load(pm)
+			So(err, ShouldErrLike, "missing required fields")
+			So(missing(err), ShouldResemble, []string{"Owner", "Tags", "Sub.Owner"})
+		})
+
+		Convey("present but null is missing", func() {
+			pm["Owner"] = mp(nil)
+			pm["Tags"] = PropertySlice{mp(nil)}
+			So(missing(GetPLS(&Required{}).Load(pm)), ShouldResemble, []string{"Owner", "Tags"})
+		})
+
+		Convey("a single non-null value satisfies a slice field", func() {
+			pm["Tags"] = PropertySlice{mp(nil), mp("a")}
+			So(GetPLS(&Required{}).Load(pm), ShouldBeNil)
+		})
+
+		Convey("every slice element must have the field", func() {
+			pm["Items.Owner"] = PropertySlice{mp("x"), mp(nil)}
+			So(missing(GetPLS(&Required{}).Load(pm)), ShouldResemble, []string{"Items[1].Owner"})
+
+			pm["Items.Owner"] = PropertySlice{mp("x")}
+			So(missing(GetPLS(&Required{}).Load(pm)), ShouldResemble, []string{"Items[1].Owner"})
+
+			delete(pm, "Items.Owner")
+			So(missing(GetPLS(&Required{}).Load(pm)), ShouldResemble,
+				[]string{"Items[0].Owner", "Items[1].Owner"})
+		})
+
+		Convey("an empty slice of structs is fine", func() {
+			delete(pm, "Items.Owner")
+			delete(pm, "Items.Note")
+			So(GetPLS(&Required{}).Load(pm), ShouldBeNil)
+		})
+
+		Convey("is not an ErrFieldMismatch", func() {
+			delete(pm, "Owner")
+			pm["Other"] = mp("not an int")
+			err := GetPLS(&Required{}).Load(pm).(errors.MultiError)
+			So(err, ShouldHaveLength, 2)
+			mismatches := 0
+			for _, e := range err {
+				if _, ok := e.(*ErrFieldMismatch); ok {
+					mismatches++
+				}
+			}
+			So(mismatches, ShouldEqual, 1)
+			So(missing(err), ShouldResemble, []string{"Owner"})
+		})
+
+		Convey("mismatched values diverted to extra are missing", func() {
+			type RequiredExtra struct {
+				Owner string `gae:",required"`
+
+				Extra PropertyMap `gae:",extra"`
+			}
+			re := &RequiredExtra{}
+			err := GetPLS(re).Load(PropertyMap{"Owner": mp(100)})
+			So(err, ShouldErrLike, `missing required fields ["Owner"]`)
+			So(re.Extra, ShouldResemble, PropertyMap{"Owner": PropertySlice{mp(100)}})
+		})
+
+		Convey("Save is unaffected", func() {
+			props, err := GetPLS(&Required{}).Save(false)
+			So(err, ShouldBeNil)
+			So(props["Owner"], ShouldResemble, mp(""))
+		})
+
+		Convey("struct fields cannot be required", func() {
+			type BadRequired struct {
+				Sub RequiredInner `gae:",required"`
+			}
+			So(func() { GetPLS(&BadRequired{}) }, ShouldPanicLike, "cannot be required")
+		})
+	})
+}
@@ -634,14 +634,7 @@ func (p *Property) Compare(other *Property) int {
 		return cmpFloat(a.Lng, b.Lng)
 
 	case PTKey:
-		a, b := av.(*Key), bv.(*Key)
-		if a.Equal(b) {
-			return 0
-		}
-		if b.Less(a) {
-			return 1
-		}
-		return -1
+		return KeyCompare(av.(*Key), bv.(*Key))
 
 	default:
 		panic(fmt.Errorf("uncomparable type: %s", t))
@@ -873,6 +866,11 @@ type PropertyData interface {
 //
 // Additionally, Save returns a copy of the map with the meta keys omitted (e.g.
 // these keys are not going to be serialized to the datastore).
+//
+// PropertyMap implements PropertyLoadSaver and MetaGetterSetter, so it can be
+// passed directly to Get, Put and friends. Its key is described either by a
+// "$key" entry, or by separate "$kind", "$id" and "$parent" entries. If both
+// are present, "$key" takes precedence (see GetMeta and SetMeta).
 type PropertyMap map[string]PropertyData
 
 var _ PropertyLoadSaver = PropertyMap(nil)
@@ -902,7 +900,32 @@ func (pm PropertyMap) Save(withMeta bool) (PropertyMap, error) {
 
 // GetMeta implements PropertyLoadSaver.GetMeta, and returns the current value
 // associated with the metadata key.
+//
+// If the map has a "$key" entry, it takes precedence over separate "$kind",
+// "$id" and "$parent" entries: those values are derived from the "$key"
+// instead. An incomplete "$key" has no "id", and a root "$key" has no
+// "parent".
 func (pm PropertyMap) GetMeta(key string) (interface{}, bool) {
+	if k := pm.metaKey(); k != nil {
+		switch key {
+		case "kind":
+			return k.Kind(), true
+		case "id":
+			switch {
+			case k.StringID() != "":
+				return k.StringID(), true
+			case k.IntID() != 0:
+				return k.IntID(), true
+			}
+			return nil, false
+		case "parent":
+			if par := k.Parent(); par != nil {
+				return par, true
+			}
+			return nil, false
+		}
+	}
+
 	pslice := pm.Slice("$" + key)
 	if len(pslice) > 0 {
 		return pslice[0].Value(), true
@@ -910,6 +933,17 @@ func (pm PropertyMap) GetMeta(key string) (interface{}, bool) {
 	return nil, false
 }
 
+// metaKey returns the *Key stored in the "$key" entry, or nil if there is
+// none.
+func (pm PropertyMap) metaKey() *Key {
+	if pslice := pm.Slice("$key"); len(pslice) > 0 {
+		if k, ok := pslice[0].Value().(*Key); ok {
+			return k
+		}
+	}
+	return nil
+}
+
 // GetAllMeta implements PropertyLoadSaver.GetAllMeta.
 func (pm PropertyMap) GetAllMeta() PropertyMap {
 	ret := make(PropertyMap, 8)
@@ -921,17 +955,73 @@ func (pm PropertyMap) GetAllMeta() PropertyMap {
 	return ret
 }
 
-// SetMeta implements PropertyLoadSaver.SetMeta. It will only return an error
+// SetMeta implements PropertyLoadSaver.SetMeta. It will only return false
 // if `val` has an invalid type (e.g. not one supported by Property).
+//
+// The value is stored as a NoIndex Property under "$"+key. If the map has a
+// "$key" entry and key is "kind", "id" or "parent", the "$key" entry is also
+// rewritten to reflect the new value, so that GetMeta stays consistent. In
+// that case val must have a type appropriate for that part of a Key.
 func (pm PropertyMap) SetMeta(key string, val interface{}) bool {
 	prop := Property{}
 	if err := prop.SetValue(val, NoIndex); err != nil {
 		return false
 	}
+
+	switch key {
+	case "kind", "id", "parent":
+		if k := pm.metaKey(); k != nil {
+			k, ok := withKeyMeta(k, key, prop.Value())
+			if !ok {
+				return false
+			}
+			pm["$key"] = MkPropertyNI(k)
+		}
+	}
+
 	pm["$"+key] = prop
 	return true
 }
 
+// withKeyMeta returns a copy of k with its "kind", "id" or "parent" replaced
+// by val. It returns false if val has the wrong type for that part of k.
+func withKeyMeta(k *Key, key string, val interface{}) (*Key, bool) {
+	kind, sid, iid, par := k.Kind(), k.StringID(), k.IntID(), k.Parent()
+	switch v := val.(type) {
+	case string:
+		switch key {
+		case "kind":
+			kind = v
+		case "id":
+			sid, iid = v, 0
+		default:
+			return nil, false
+		}
+	case int64:
+		if key != "id" {
+			return nil, false
+		}
+		sid, iid = "", v
+	case *Key:
+		if key != "parent" {
+			return nil, false
+		}
+		par = v
+	case nil:
+		switch key {
+		case "id":
+			sid, iid = "", 0
+		case "parent":
+			par = nil
+		default:
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	return k.KeyContext().NewKey(kind, sid, iid, par), true
+}
+
 // Problem implements PropertyLoadSaver.Problem. It ALWAYS returns nil.
 func (pm PropertyMap) Problem() error {
 	return nil
@@ -248,6 +248,77 @@ func TestDSPropertyMapImpl(t *testing.T) {
 					So(pm.SetMeta("sup", complex(100, 20)), ShouldBeFalse)
 				})
 			})
+
+			Convey("$key", func() {
+				kc := MkKeyContext("aid", "ns")
+				parent := kc.MakeKey("Parent", 1)
+
+				Convey("provides kind, id and parent", func() {
+					pm := PropertyMap{"$key": MkPropertyNI(kc.MakeKey("Parent", 1, "Kind", "name"))}
+					So(GetMetaDefault(pm, "kind", ""), ShouldEqual, "Kind")
+					So(GetMetaDefault(pm, "id", ""), ShouldEqual, "name")
+					So(GetMetaDefault(pm, "parent", nil), ShouldResemble, parent)
+
+					pm = PropertyMap{"$key": MkPropertyNI(kc.MakeKey("Kind", 10))}
+					So(GetMetaDefault(pm, "id", 0), ShouldEqual, 10)
+					_, ok := pm.GetMeta("parent")
+					So(ok, ShouldBeFalse)
+				})
+
+				Convey("an incomplete $key has no id", func() {
+					pm := PropertyMap{"$key": MkPropertyNI(kc.NewKey("Kind", "", 0, nil))}
+					_, ok := pm.GetMeta("id")
+					So(ok, ShouldBeFalse)
+					So(GetMetaDefault(pm, "kind", ""), ShouldEqual, "Kind")
+				})
+
+				Convey("takes precedence over $kind, $id and $parent", func() {
+					pm := PropertyMap{
+						"$key":    MkPropertyNI(kc.MakeKey("Kind", "name")),
+						"$kind":   MkPropertyNI("Other"),
+						"$id":     MkPropertyNI(100),
+						"$parent": MkPropertyNI(parent),
+					}
+					So(GetMetaDefault(pm, "kind", ""), ShouldEqual, "Kind")
+					So(GetMetaDefault(pm, "id", ""), ShouldEqual, "name")
+					_, ok := pm.GetMeta("parent")
+					So(ok, ShouldBeFalse)
+
+					key, err := newKeyObjErr(kc, pm)
+					So(err, ShouldBeNil)
+					So(key, ShouldResemble, kc.MakeKey("Kind", "name"))
+				})
+
+				Convey("SetMeta rewrites $key", func() {
+					pm := PropertyMap{"$key": MkPropertyNI(kc.MakeKey("Kind", "name"))}
+
+					So(pm.SetMeta("id", 20), ShouldBeTrue)
+					So(pm["$key"], ShouldResemble, MkPropertyNI(kc.MakeKey("Kind", 20)))
+					So(pm["$id"], ShouldResemble, MkPropertyNI(20))
+
+					So(pm.SetMeta("parent", parent), ShouldBeTrue)
+					So(pm["$key"], ShouldResemble, MkPropertyNI(kc.MakeKey("Parent", 1, "Kind", 20)))
+
+					So(pm.SetMeta("kind", "Other"), ShouldBeTrue)
+					So(pm["$key"], ShouldResemble, MkPropertyNI(kc.MakeKey("Parent", 1, "Other", 20)))
+
+					So(pm.SetMeta("parent", nil), ShouldBeTrue)
+					So(pm["$key"], ShouldResemble, MkPropertyNI(kc.MakeKey("Other", 20)))
+
+					Convey("rejecting values of the wrong type", func() {
+						So(pm.SetMeta("kind", 20), ShouldBeFalse)
+						So(pm.SetMeta("parent", "nope"), ShouldBeFalse)
+						So(pm["$key"], ShouldResemble, MkPropertyNI(kc.MakeKey("Other", 20)))
+						So(pm["$kind"], ShouldResemble, MkPropertyNI("Other"))
+					})
+				})
+
+				Convey("SetMeta(key) replaces $key", func() {
+					pm := PropertyMap{"$kind": MkPropertyNI("Kind")}
+					So(pm.SetMeta("key", kc.MakeKey("Kind", 3)), ShouldBeTrue)
+					So(GetMetaDefault(pm, "id", 0), ShouldEqual, 3)
+				})
+			})
 		})
 	})
 }
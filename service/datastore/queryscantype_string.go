@@ -0,0 +1,28 @@
+// Code generated by "stringer -type=QueryScanType"; DO NOT EDIT.
+
+package datastore
+
+import "strconv"
+
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[KindlessScan-0]
+	_ = x[KindScan-1]
+	_ = x[BuiltinIndexScan-2]
+	_ = x[CompositeIndexScan-3]
+	_ = x[NamespaceScan-4]
+}
+
+const _QueryScanType_name = "KindlessScanKindScanBuiltinIndexScanCompositeIndexScanNamespaceScan"
+
+var _QueryScanType_index = [...]uint8{0, 12, 20, 36, 54, 67}
+
+func (i QueryScanType) String() string {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_QueryScanType_index)-1 {
+		return "QueryScanType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _QueryScanType_name[_QueryScanType_index[idx]:_QueryScanType_index[idx+1]]
+}
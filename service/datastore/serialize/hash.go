@@ -0,0 +1,105 @@
+// Copyright 2017 The LUCI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serialize
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"sort"
+	"strings"
+
+	ds "go.chromium.org/gae/service/datastore"
+	"go.chromium.org/luci/common/data/cmpbin"
+	"go.chromium.org/luci/common/data/stringset"
+)
+
+// HashPropertyMap returns a stable SHA-256 content hash of pm, suitable for
+// use as an entity checksum or ETag.
+//
+// Meta properties (those whose names begin with '$') are excluded, except for
+// the ones named in includeMeta.
+//
+// The hash is computed over the following encoding, which is part of this
+// package's stable API and will not change between processes or versions:
+//   [cmpbin(name) ++ cmpbin(uint(len(values))) ++ [value]*]*
+// with names in ascending order, and each name's values in their PropertyMap
+// order. Properties with no values are skipped. Each value is encoded as
+// WriteProperty(buf, WithContext, v) would encode it, except that the index
+// bit (0x80) of its type byte is always set, PTBytes values are encoded as
+// PTString values, and -0 is encoded as +0.
+//
+// PropertyMaps which only differ in the representation of their values hash
+// equal. In particular:
+//   - IndexSetting is ignored.
+//   - A single Property and a PropertySlice containing only it are the same.
+//   - PTString and PTBytes values with the same bytes are the same.
+//   - PTTime values are rounded to microseconds.
+//   - -0 and +0 are the same.
+// Anything else, including a change of a value's type or of a Key value's
+// AppID or Namespace, changes the hash.
+func HashPropertyMap(pm ds.PropertyMap, includeMeta ...string) []byte {
+	meta := stringset.NewFromSlice(includeMeta...)
+	names := make([]string, 0, len(pm))
+	for name := range pm {
+		if strings.HasPrefix(name, "$") && !meta.Has(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := bytes.Buffer{}
+	for _, name := range names {
+		vals := pm.Slice(name)
+		if len(vals) == 0 {
+			continue
+		}
+		_, err := cmpbin.WriteString(&buf, name)
+		panicIf(err)
+		_, err = cmpbin.WriteUint(&buf, uint64(len(vals)))
+		panicIf(err)
+		for _, v := range vals {
+			panicIf(writeHashValue(&buf, v))
+		}
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return sum[:]
+}
+
+// HashStruct is a convenience function which returns the HashPropertyMap of
+// obj as it would be saved by its default struct PropertyLoadSaver (see
+// datastore.GetPLS).
+//
+// Meta fields (e.g. `gae:"$id"`) are only saved, and included in the hash, if
+// they're named in includeMeta.
+func HashStruct(obj interface{}, includeMeta ...string) ([]byte, error) {
+	pm, err := ds.GetPLS(obj).Save(len(includeMeta) > 0)
+	if err != nil {
+		return nil, err
+	}
+	return HashPropertyMap(pm, includeMeta...), nil
+}
+
+// writeHashValue writes the HashPropertyMap encoding of p to buf.
+func writeHashValue(buf WriteBuffer, p ds.Property) error {
+	if p.Type() == ds.PTBytes {
+		v, err := p.Project(ds.PTString)
+		if err != nil {
+			return err
+		}
+		p = ds.MkProperty(v)
+	}
+	return writePropertyImpl(buf, WithContext, &p, false, true)
+}
@@ -0,0 +1,201 @@
+// Copyright 2017 The LUCI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serialize
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"testing"
+	"time"
+
+	"go.chromium.org/gae/service/blobstore"
+	ds "go.chromium.org/gae/service/datastore"
+	"go.chromium.org/luci/common/data/cmpbin"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type hashStruct struct {
+	_kind string `gae:"$kind,Hashed"`
+	ID    int64  `gae:"$id"`
+
+	Name string
+	Tags []string
+}
+
+func TestHashPropertyMap(t *testing.T) {
+	t.Parallel()
+
+	hash := func(pm ds.PropertyMap, includeMeta ...string) string {
+		return hex.EncodeToString(HashPropertyMap(pm, includeMeta...))
+	}
+
+	kc := ds.MkKeyContext("dev~app", "ns")
+	testTime := time.Date(2017, time.March, 1, 2, 3, 4, 5006000, time.UTC)
+
+	Convey("HashPropertyMap", t, func() {
+		Convey("golden values", func() {
+			// These hashes are part of the stable API. If they change, existing
+			// stored checksums break.
+			So(hash(nil), ShouldEqual,
+				"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+			So(hash(ds.PropertyMap{
+				"Null":    mp(nil),
+				"Bool":    mp(true),
+				"Int":     mp(-7),
+				"Float":   mp(2.5),
+				"String":  mp("hello"),
+				"Bytes":   mp([]byte("\x00\xff")),
+				"Time":    mp(testTime),
+				"Geo":     mp(ds.GeoPoint{Lat: 1.5, Lng: -2}),
+				"Key":     mp(kc.MakeKey("Parent", "p", "Child", 10)),
+				"BlobKey": mp(blobstore.Key("bk")),
+				"Multi":   ds.PropertySlice{mp(1), mp("two"), mp(3.0)},
+			}), ShouldEqual,
+				"93cd6bae019f95908563b67b7cb06f10eb82141d76b03159fea37c88cb583c40")
+			So(hash(ds.PropertyMap{
+				"$kind": mp("Kind"),
+				"$id":   mp(1),
+				"Value": mp(100),
+			}, "$kind"), ShouldEqual,
+				"e853413ac3a21126f77f063580ac7654973b236293f64fffd0ad9d9ed150827e")
+		})
+
+		Convey("hashes the documented encoding", func() {
+			buf := &bytes.Buffer{}
+			cmpbin.WriteString(buf, "A")
+			cmpbin.WriteUint(buf, 1)
+			WriteProperty(buf, WithContext, mp(1))
+			cmpbin.WriteString(buf, "B")
+			cmpbin.WriteUint(buf, 3)
+			WriteProperty(buf, WithContext, mp("x"))
+			WriteProperty(buf, WithContext, mp(true))
+			WriteProperty(buf, WithContext, mp(kc.MakeKey("Kind", 1)))
+			sum := sha256.Sum256(buf.Bytes())
+
+			So(HashPropertyMap(ds.PropertyMap{
+				"B": ds.PropertySlice{mp("x"), mp(true), mp(kc.MakeKey("Kind", 1))},
+				"A": mp(1),
+			}), ShouldResemble, sum[:])
+		})
+
+		Convey("is independent of representation", func() {
+			a := ds.PropertyMap{
+				"S":    mp("value"),
+				"T":    mp(testTime),
+				"F":    mp(0.0),
+				"Idx":  mp(1),
+				"One":  mp("single"),
+				"None": ds.PropertySlice{},
+			}
+			b := ds.PropertyMap{
+				"S":   mp([]byte("value")),
+				"T":   mp(testTime.Add(499)),
+				"F":   mp(math.Copysign(0, -1)),
+				"Idx": mpNI(1),
+				"One": ds.PropertySlice{mp("single")},
+			}
+			So(hash(a), ShouldEqual, hash(b))
+		})
+
+		Convey("detects changes", func() {
+			base := ds.PropertyMap{
+				"A": mp(1),
+				"B": ds.PropertySlice{mp("x"), mp("y")},
+			}
+			So(hash(base), ShouldNotEqual, hash(ds.PropertyMap{"A": mp(2), "B": base["B"]}))
+			So(hash(base), ShouldNotEqual, hash(ds.PropertyMap{"A": mp(1), "B": ds.PropertySlice{mp("y"), mp("x")}}))
+			So(hash(base), ShouldNotEqual, hash(ds.PropertyMap{"A": mp(1), "B": ds.PropertySlice{mp("x")}}))
+			So(hash(base), ShouldNotEqual, hash(ds.PropertyMap{"A": mp(1), "C": base["B"]}))
+			So(hash(base), ShouldNotEqual, hash(ds.PropertyMap{"A": mp("1"), "B": base["B"]}))
+
+			Convey("including changes of type", func() {
+				So(hash(ds.PropertyMap{"T": mp(testTime)}), ShouldNotEqual,
+					hash(ds.PropertyMap{"T": mp(ds.TimeToInt(testTime))}))
+				So(hash(ds.PropertyMap{"S": mp(blobstore.Key("value"))}), ShouldNotEqual,
+					hash(ds.PropertyMap{"S": mp("value")}))
+			})
+
+			Convey("including a Key's AppID and Namespace", func() {
+				k := hash(ds.PropertyMap{"K": mp(kc.MakeKey("Kind", 1))})
+				So(k, ShouldNotEqual, hash(ds.PropertyMap{"K": mp(ds.MkKeyContext("other~app", "ns").MakeKey("Kind", 1))}))
+				So(k, ShouldNotEqual, hash(ds.PropertyMap{"K": mp(ds.MkKeyContext("dev~app", "other").MakeKey("Kind", 1))}))
+			})
+
+			Convey("including across name and value boundaries", func() {
+				So(hash(ds.PropertyMap{"A": mp("BC")}), ShouldNotEqual, hash(ds.PropertyMap{"AB": mp("C")}))
+				So(hash(ds.PropertyMap{"A": ds.PropertySlice{mp("x"), mp("y")}}), ShouldNotEqual,
+					hash(ds.PropertyMap{"A": mp("x"), "B": mp("y")}))
+			})
+		})
+
+		Convey("meta", func() {
+			pm := ds.PropertyMap{
+				"$kind": mp("Kind"),
+				"$id":   mp(1),
+				"Value": mp(100),
+			}
+
+			Convey("is excluded by default", func() {
+				So(hash(pm), ShouldEqual, hash(ds.PropertyMap{"Value": mp(100)}))
+			})
+
+			Convey("can be included by name", func() {
+				So(hash(pm, "$kind"), ShouldNotEqual, hash(pm))
+				So(hash(pm, "$kind"), ShouldNotEqual, hash(pm, "$kind", "$id"))
+
+				other := ds.PropertyMap{"$kind": mp("Other"), "$id": mp(1), "Value": mp(100)}
+				So(hash(other, "$id"), ShouldEqual, hash(pm, "$id"))
+				So(hash(other, "$kind"), ShouldNotEqual, hash(pm, "$kind"))
+			})
+		})
+	})
+
+	Convey("HashStruct", t, func() {
+		hs := &hashStruct{ID: 1, Name: "bob", Tags: []string{"a", "b"}}
+
+		h, err := HashStruct(hs)
+		So(err, ShouldBeNil)
+		So(h, ShouldResemble, HashPropertyMap(ds.PropertyMap{
+			"Name": mp("bob"),
+			"Tags": ds.PropertySlice{mp("a"), mp("b")},
+		}))
+
+		Convey("with meta", func() {
+			h, err := HashStruct(hs, "$kind", "$id")
+			So(err, ShouldBeNil)
+			So(h, ShouldResemble, HashPropertyMap(ds.PropertyMap{
+				"$kind": mp("Hashed"),
+				"$id":   mp(1),
+				"Name":  mp("bob"),
+				"Tags":  ds.PropertySlice{mp("a"), mp("b")},
+			}, "$kind", "$id"))
+
+			hs.ID = 2
+			h2, err := HashStruct(hs, "$kind", "$id")
+			So(err, ShouldBeNil)
+			So(h2, ShouldNotResemble, h)
+
+			h3, err := HashStruct(hs)
+			So(err, ShouldBeNil)
+			So(h3, ShouldResemble, HashPropertyMap(ds.PropertyMap{
+				"Name": mp("bob"),
+				"Tags": ds.PropertySlice{mp("a"), mp("b")},
+			}))
+		})
+	})
+}
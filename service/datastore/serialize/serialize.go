@@ -225,19 +225,22 @@ func ReadTime(buf ReadBuffer) (time.Time, error) {
 // way that it does for WriteKey, but only has an effect if `p` contains a
 // Key as its IndexValue.
 func WriteProperty(buf WriteBuffer, context KeyContext, p ds.Property) error {
-	return writePropertyImpl(buf, context, &p, false)
+	return writePropertyImpl(buf, context, &p, false, false)
 }
 
 // WriteIndexProperty writes a Property to the buffer as its native index type.
 // `context` behaves the same way that it does for WriteKey, but only has an
 // effect if `p` contains a Key as its IndexValue.
 func WriteIndexProperty(buf WriteBuffer, context KeyContext, p ds.Property) error {
-	return writePropertyImpl(buf, context, &p, true)
+	return writePropertyImpl(buf, context, &p, true, false)
 }
 
-// writePropertyImpl is an implementation of WriteProperty and
-// WriteIndexProperty.
-func writePropertyImpl(buf WriteBuffer, context KeyContext, p *ds.Property, index bool) (err error) {
+// writePropertyImpl is an implementation of WriteProperty,
+// WriteIndexProperty, WriteIndexValue and HashPropertyMap's value encoding.
+//
+// If indexValue is true, p is written as an indexed value regardless of its
+// IndexSetting, and -0 is written as +0.
+func writePropertyImpl(buf WriteBuffer, context KeyContext, p *ds.Property, index, indexValue bool) (err error) {
 	defer recoverTo(&err)
 
 	it, v := p.IndexTypeAndValue()
@@ -245,11 +248,16 @@ func writePropertyImpl(buf WriteBuffer, context KeyContext, p *ds.Property, inde
 		it = p.Type()
 	}
 	typb := byte(it)
-	if p.IndexSetting() != ds.NoIndex {
+	if indexValue || p.IndexSetting() != ds.NoIndex {
 		typb |= 0x80
 	}
 	panicIf(buf.WriteByte(typb))
 
+	if f, ok := v.(float64); ok && f == 0 && indexValue {
+		// -0 and +0 compare as equal, so they must have the same encoding.
+		v = float64(0)
+	}
+
 	err = writeIndexValue(buf, context, v)
 	return
 }
@@ -286,6 +294,32 @@ func writeIndexValue(buf WriteBuffer, context KeyContext, v interface{}) (err er
 	return
 }
 
+// WriteIndexValue writes the index representation of p to the buffer: a
+// leading type byte followed by p's index value.
+//
+// The encoding is chosen so that the bytewise order of two encoded values is
+// the same as the order that datastore would sort them in (as implemented by
+// Property.Compare), across all property types. p is always encoded as an
+// indexed value, regardless of its IndexSetting, and Key values are written
+// WithoutContext.
+//
+// The output can be read back with ReadProperty.
+func WriteIndexValue(buf WriteBuffer, p ds.Property) error {
+	return writePropertyImpl(buf, WithoutContext, &p, true, true)
+}
+
+// WriteIndexValueInverted is the same as WriteIndexValue, except that every
+// written byte is inverted. This is the encoding used for descending index
+// columns.
+//
+// The output can be read back with ReadProperty on an InvertibleBuffer with
+// inversion enabled.
+func WriteIndexValueInverted(buf WriteBuffer, p ds.Property) error {
+	ib := Invertible(buf)
+	ib.SetInvert(true)
+	return WriteIndexValue(ib, p)
+}
+
 // ReadProperty reads a Property from the buffer. `context` and `kc` behave the
 // same way they do for ReadKey, but only have an effect if the decoded property
 // has a Key value.
@@ -523,7 +557,7 @@ func PropertySlice(vals ds.PropertySlice) SerializedPslice {
 			continue
 		}
 
-		data := ToBytes(v)
+		data := indexValueBytes(v)
 		dataS := string(data)
 		if !dups.Add(dataS) {
 			continue
@@ -545,9 +579,9 @@ type SerializedPmap map[string]SerializedPslice
 func PropertyMapPartially(k *ds.Key, pm ds.PropertyMap) (ret SerializedPmap) {
 	ret = make(SerializedPmap, len(pm)+2)
 	if k != nil {
-		ret["__key__"] = [][]byte{ToBytes(ds.MkProperty(k))}
+		ret["__key__"] = [][]byte{indexValueBytes(ds.MkProperty(k))}
 		for k != nil {
-			ret["__ancestor__"] = append(ret["__ancestor__"], ToBytes(ds.MkProperty(k)))
+			ret["__ancestor__"] = append(ret["__ancestor__"], indexValueBytes(ds.MkProperty(k)))
 			k = k.Parent()
 		}
 	}
@@ -560,6 +594,16 @@ func PropertyMapPartially(k *ds.Key, pm ds.PropertyMap) (ret SerializedPmap) {
 	return
 }
 
+// indexValueBytes returns the WriteIndexValue encoding of p, panicking if p
+// cannot be encoded.
+func indexValueBytes(p ds.Property) []byte {
+	buf := bytes.Buffer{}
+	if err := WriteIndexValue(&buf, p); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
 func toBytesErr(i interface{}, ctx KeyContext) (ret []byte, err error) {
 	buf := bytes.Buffer{}
 
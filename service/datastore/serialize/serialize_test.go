@@ -18,6 +18,8 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"math"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -526,3 +528,182 @@ func TestPartialSerialization(t *testing.T) {
 		})
 	})
 }
+
+func TestIndexValueOrdering(t *testing.T) {
+	t.Parallel()
+
+	Convey("WriteIndexValue", t, func() {
+		r := rand.New(rand.NewSource(0))
+		kc := ds.MkKeyContext("dev~app", "ns")
+
+		randString := func() string {
+			ret := make([]byte, r.Intn(6))
+			for i := range ret {
+				// Bias towards a small alphabet so that we get shared prefixes.
+				ret[i] = "\x00\x01ab\xfe\xff"[r.Intn(6)]
+			}
+			return string(ret)
+		}
+		randFloat := func() float64 {
+			switch r.Intn(8) {
+			case 0:
+				return math.Inf(1)
+			case 1:
+				return math.Inf(-1)
+			case 2:
+				return math.Copysign(0, -1)
+			case 3:
+				return 0
+			default:
+				return r.NormFloat64() * 1000
+			}
+		}
+		randKey := func() *ds.Key {
+			var k *ds.Key
+			for n := r.Intn(3) + 1; n > 0; n-- {
+				kind := []string{"A", "B"}[r.Intn(2)]
+				if r.Intn(2) == 0 {
+					k = kc.NewKey(kind, "", r.Int63n(5)+1, k)
+				} else {
+					k = kc.NewKey(kind, randString()+"id", 0, k)
+				}
+			}
+			return k
+		}
+		randProp := func() ds.Property {
+			switch r.Intn(10) {
+			case 0:
+				return mp(nil)
+			case 1:
+				return mp(r.Int63n(200) - 100)
+			case 2:
+				return mp(time.Unix(r.Int63n(1e6), r.Int63n(1e9)).UTC())
+			case 3:
+				return mp(r.Intn(2) == 0)
+			case 4:
+				return mp(randString())
+			case 5:
+				return mp([]byte(randString()))
+			case 6:
+				return mp(blobstore.Key(randString()))
+			case 7:
+				return mp(randFloat())
+			case 8:
+				return mp(ds.GeoPoint{Lat: r.Float64()*180 - 90, Lng: r.Float64()*360 - 180})
+			default:
+				return mp(randKey())
+			}
+		}
+
+		props := make([]ds.Property, 300)
+		for i := range props {
+			props[i] = randProp()
+		}
+
+		encode := func(p ds.Property, inverted bool) []byte {
+			buf := &bytes.Buffer{}
+			if inverted {
+				So(WriteIndexValueInverted(buf, p), ShouldBeNil)
+			} else {
+				So(WriteIndexValue(buf, p), ShouldBeNil)
+			}
+			return buf.Bytes()
+		}
+		sign := func(i int) int {
+			switch {
+			case i < 0:
+				return -1
+			case i > 0:
+				return 1
+			}
+			return 0
+		}
+
+		Convey("sorts the same as Property.Compare", func() {
+			asc := make([][]byte, len(props))
+			desc := make([][]byte, len(props))
+			for i, p := range props {
+				asc[i] = encode(p, false)
+				desc[i] = encode(p, true)
+			}
+
+			for i := range props {
+				for j := range props {
+					want := sign(props[i].Compare(&props[j]))
+					if got := sign(bytes.Compare(asc[i], asc[j])); got != want {
+						So(fmt.Sprintf("%v vs %v: %d", props[i], props[j], got), ShouldEqual,
+							fmt.Sprintf("%v vs %v: %d", props[i], props[j], want))
+					}
+					if got := sign(bytes.Compare(desc[i], desc[j])); got != -want {
+						So(fmt.Sprintf("%v vs %v: %d", props[i], props[j], got), ShouldEqual,
+							fmt.Sprintf("%v vs %v: %d", props[i], props[j], -want))
+					}
+				}
+			}
+		})
+
+		Convey("ignores the IndexSetting", func() {
+			So(encode(mpNI("hi"), false), ShouldResemble, encode(mp("hi"), false))
+		})
+
+		Convey("round trips", func() {
+			for _, p := range props {
+				dec, err := ReadProperty(bytes.NewBuffer(encode(p, false)), WithoutContext, kc)
+				So(err, ShouldBeNil)
+				So(dec.Compare(&p), ShouldEqual, 0)
+
+				ib := Invertible(bytes.NewBuffer(encode(p, true)))
+				ib.SetInvert(true)
+				dec, err = ReadProperty(ib, WithoutContext, kc)
+				So(err, ShouldBeNil)
+				So(dec.Compare(&p), ShouldEqual, 0)
+			}
+		})
+	})
+}
+
+func TestKeyOrdering(t *testing.T) {
+	t.Parallel()
+
+	Convey("WriteKey byte order agrees with ds.KeyCompare", t, func() {
+		r := rand.New(rand.NewSource(0))
+		pick := func(vals ...string) string { return vals[r.Intn(len(vals))] }
+
+		keys := make([]*ds.Key, 200)
+		for i := range keys {
+			kc := ds.MkKeyContext(pick("a", "a~b", "b"), pick("", "ns", "ns\x00"))
+			var k *ds.Key
+			for n := r.Intn(3) + 1; n > 0; n-- {
+				if r.Intn(2) == 0 {
+					k = kc.NewKey(pick("A", "AB", "B"), "", r.Int63n(20)+1, k)
+				} else {
+					k = kc.NewKey(pick("A", "AB", "B"), pick("1", "10", "2", "\x00", "x"), 0, k)
+				}
+			}
+			keys[i] = k
+		}
+
+		for _, ctx := range []KeyContext{WithContext, WithoutContext} {
+			enc := make([][]byte, len(keys))
+			for i, k := range keys {
+				buf := &bytes.Buffer{}
+				So(WriteKey(buf, ctx, k), ShouldBeNil)
+				enc[i] = buf.Bytes()
+			}
+
+			for i, a := range keys {
+				for j, b := range keys {
+					if ctx == WithoutContext && (a.AppID() != b.AppID() || a.Namespace() != b.Namespace()) {
+						continue
+					}
+					want := ds.KeyCompare(a, b)
+					got := bytes.Compare(enc[i], enc[j])
+					if got != want {
+						So(fmt.Sprintf("%s vs %s: %d", a, b, got), ShouldEqual,
+							fmt.Sprintf("%s vs %s: %d", a, b, want))
+					}
+				}
+			}
+		}
+	})
+}
@@ -19,6 +19,76 @@ type TestingSnapshot interface {
 	ImATestingSnapshot()
 }
 
+// QueryScanType describes the kind of table that a QueryScan reads.
+type QueryScanType int
+
+//go:generate stringer -type=QueryScanType
+
+const (
+	// KindlessScan scans the primary entity table. It's used for kindless
+	// queries.
+	KindlessScan QueryScanType = iota
+
+	// KindScan scans the builtin Kind/__key__ index. It's the fallback used when
+	// the query has no filters or sort orders on regular properties.
+	KindScan
+
+	// BuiltinIndexScan scans one of the automatic single-property indexes
+	// (Kind/Prop/__key__ or Kind/-Prop/__key__).
+	BuiltinIndexScan
+
+	// CompositeIndexScan scans a user-defined composite index.
+	CompositeIndexScan
+
+	// NamespaceScan enumerates the namespaces in the datastore. It's used for
+	// __namespace__ metadata queries.
+	NamespaceScan
+)
+
+// QueryScan describes a single index range scan which is part of a QueryPlan.
+type QueryScan struct {
+	Type QueryScanType
+
+	// Index is the normalized (see IndexDefinition.Normalize) index that this
+	// scan reads. It's nil for KindlessScan and NamespaceScan.
+	Index *IndexDefinition
+
+	// Prefix is the encoded prefix which every row in this scan shares (e.g. the
+	// values of the equality filters served by Index).
+	Prefix []byte
+
+	// Start and End are the encoded bounds of the scan, relative to Prefix. Start
+	// is inclusive and End is exclusive. A nil End means that the scan continues
+	// to the end of Prefix.
+	Start []byte
+	End   []byte
+
+	// Rows is the number of index rows which currently fall within this scan's
+	// range.
+	Rows int
+}
+
+// QueryPlan describes how a fake datastore implementation would execute a
+// query. It's returned by QueryExplainer.ExplainQuery, and is intended for
+// debugging and for assertions in tests.
+type QueryPlan struct {
+	// Null is true if the query can be proven to return no results without
+	// reading any index (e.g. it has contradictory filters, or an index it
+	// requires is empty). Scans is empty when Null is true.
+	Null bool
+
+	// Scans is the list of index scans used to service the query.
+	Scans []QueryScan
+
+	// Merged is true if the results of multiple Scans are merged to produce the
+	// query results (e.g. a zigzag merge join).
+	Merged bool
+
+	// EstimatedRows is the number of index rows which the query may examine.
+	// This is the sum of Rows over all Scans, and so is an upper bound.
+	EstimatedRows int
+}
+
 // Testable is the testable interface for fake datastore implementations.
 type Testable interface {
 	// AddIndex adds the provided index.
@@ -98,3 +168,19 @@ type Testable interface {
 	// If c is nil, default constraints will be set.
 	SetConstraints(c *Constraints) error
 }
+
+// QueryExplainer is an optional interface which a Testable may implement to
+// describe how it executes queries. Use a type assertion to check for it:
+//
+//   if qe, ok := GetTestable(c).(QueryExplainer); ok {
+//     plan, err := qe.ExplainQuery(q)
+//     ...
+//   }
+type QueryExplainer interface {
+	// ExplainQuery returns the plan which would be used to execute q, given the
+	// current indexes and data, without running it.
+	//
+	// If q requires an index which is missing, the error is the same as the one
+	// Run would return (including AutoIndex behavior).
+	ExplainQuery(q *Query) (*QueryPlan, error)
+}